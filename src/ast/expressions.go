@@ -1,12 +1,14 @@
 package ast
 
 import (
+	"math/big"
+
 	"github.com/go-parser/src/lexer"
 )
 
 // Literal expressions
 /*
-The NumberExpr class represents a numeric expression in the abstract syntax tree (AST). It has a single field Value of type float64, which stores the numerical value of the expression.
+The NumberExpr class represents a floating-point literal such as 3.14 or 1e-9 in the abstract syntax tree (AST). It has a single field Value of type float64, which stores the numerical value of the expression.
 */
 type NumberExpr struct {
 	Value float64
@@ -16,12 +18,72 @@ type NumberExpr struct {
 // It does not contain any methods or fields, but it allows for type assertions and polymorphism in the AST.
 func (n NumberExpr) expr() {}
 
+/*
+The IntegerExpr class represents an integer literal such as 42, 0xff or 1_000 in the abstract syntax tree (AST). It has a single field Value of type *big.Int, so integers of any size are kept exactly.
+*/
+type IntegerExpr struct {
+	Value *big.Int
+}
+
+func (n IntegerExpr) expr() {}
+
+/*
+The StringForm type tells which syntax a string literal was written in:
+
+QUOTED_STRING: a "..." string, whose escape sequences were decoded.
+RAW_STRING: a `...` raw string, kept exactly as written apart from line endings.
+INDENTED_STRING: a ```...``` block, a raw string whose common indentation was stripped.
+*/
+type StringForm int
+
+const (
+	QUOTED_STRING StringForm = iota
+	RAW_STRING
+	INDENTED_STRING
+)
+
+/*
+The StringExpr class represents a string literal in the abstract syntax tree (AST). Here's a succinct explanation of what each field does:
+
+Value string: This field stores the value of the string, with escape sequences decoded for quoted strings.
+Form StringForm: This field stores which syntax the string was written in.
+*/
 type StringExpr struct {
 	Value string
+	Form  StringForm
 }
 
 func (n StringExpr) expr() {}
 
+/*
+The TemplateExpr class represents an interpolated string such as "hello ${user.name}!" in the abstract syntax tree (AST). Here's a succinct explanation of what each field does:
+
+Literals []string: This field stores the decoded literal text around the expressions, so there is always one more literal than there are expressions ("hello " and "!").
+Expressions []Expr: This field stores the expressions embedded with ${...}, in source order.
+*/
+type TemplateExpr struct {
+	Literals    []string
+	Expressions []Expr
+}
+
+func (n TemplateExpr) expr() {}
+
+/*
+The BooleanExpr class represents the literal true or false in the abstract syntax tree (AST). It has a single field Value of type bool.
+*/
+type BooleanExpr struct {
+	Value bool
+}
+
+func (n BooleanExpr) expr() {}
+
+/*
+The NullExpr class represents the literal null in the abstract syntax tree (AST). It has no fields.
+*/
+type NullExpr struct{}
+
+func (n NullExpr) expr() {}
+
 type SymbolExpr struct {
 	Value string
 }
@@ -81,3 +143,102 @@ type AssignmentExpr struct {
 }
 
 func (n AssignmentExpr) expr() {}
+
+/*
+This class definition defines a CallExpr struct in Go, which represents a function call such as `println(file)` or `getFn()(x)` in an abstract syntax tree (AST). Here's a succinct explanation of what each field does:
+
+Callee Expr: This field stores the expression being called, which may be any expression, including another call.
+Arguments []Expr: This field stores the arguments in source order. It is empty, not nil, for a call without arguments.
+Parens lexer.Span: This field stores the span from the opening to the closing parenthesis, for errors that point at the argument list.
+*/
+type CallExpr struct {
+	Callee    Expr
+	Arguments []Expr
+	Parens    lexer.Span
+}
+
+func (n CallExpr) expr() {}
+
+/*
+This class definition defines a MemberExpr struct in Go, which represents a member access such as `this.directoryPath` in an abstract syntax tree (AST). Here's a succinct explanation of what each field does:
+
+Object Expr: This field stores the expression whose member is accessed, to the left of the dot.
+Property string: This field stores the name of the member, to the right of the dot.
+*/
+type MemberExpr struct {
+	Object   Expr
+	Property string
+}
+
+func (n MemberExpr) expr() {}
+
+/*
+This class definition defines a ComputedExpr struct in Go, which represents an index expression such as `array[index]` in an abstract syntax tree (AST). Here's a succinct explanation of what each field does:
+
+Object Expr: This field stores the expression being indexed, to the left of the brackets.
+Index Expr: This field stores the expression inside the brackets.
+*/
+type ComputedExpr struct {
+	Object Expr
+	Index  Expr
+}
+
+func (n ComputedExpr) expr() {}
+
+/*
+This class definition defines an ArrayLiteral struct in Go, which represents an array value such as `[1, 2, 3]` or `[...a, b]` in an abstract syntax tree (AST). Here's a succinct explanation of what each field does:
+
+Elements []Expr: This field stores the elements in source order, with a SpreadExpr for every `...` element. It is empty, not nil, for `[]`.
+*/
+type ArrayLiteral struct {
+	Elements []Expr
+}
+
+func (n ArrayLiteral) expr() {}
+
+/*
+This class definition defines a SpreadExpr struct in Go, which represents a `...a` element of an array literal in an abstract syntax tree (AST).
+It stands for every element of the array it spreads, in order. Here's a succinct explanation of what each field does:
+
+Argument Expr: This field stores the expression being spread, to the right of the `...`.
+*/
+type SpreadExpr struct {
+	Argument Expr
+}
+
+func (n SpreadExpr) expr() {}
+
+/*
+This class definition defines a NewExpr struct in Go, which represents an object instantiation in an abstract syntax tree (AST). It covers both constructor calls such as `new DirectoryReader()` and keyed initialisers such as `new Point { x: 1, y: 2 }`. Here's a succinct explanation of what each field does:
+
+Type Type: This field stores the type being instantiated, which may be qualified or generic, such as `fs.Reader` or `Map<string, number>`.
+Arguments []Expr: This field stores the constructor arguments. It is nil for a keyed initialiser.
+Fields []FieldInit: This field stores the fields of a keyed initialiser in source order. It is nil for a constructor call.
+*/
+type NewExpr struct {
+	Type      Type
+	Arguments []Expr
+	Fields    []FieldInit
+}
+
+func (n NewExpr) expr() {}
+
+/*
+The FieldInit class represents one `field: value` entry of a keyed initialiser in a NewExpr. Its Name field stores the name of the field being set and its Value field stores the expression it is set to.
+*/
+type FieldInit struct {
+	Name  string
+	Value Expr
+}
+
+/*
+The BadExpr class is a placeholder for an expression with a syntax error, such as the missing
+right hand side of `let a = ;`. The parser reports the error and leaves a BadExpr in the tree
+so the enclosing statement is kept. Its Span field holds the region of source code it replaces,
+which is empty when the expression is missing altogether.
+*/
+type BadExpr struct {
+	Span lexer.Span
+}
+
+func (b BadExpr) expr() {}
@@ -0,0 +1,193 @@
+package ast
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/go-parser/src/lexer"
+)
+
+/*
+This class definition defines a struct called `SourceFile` in Go, which holds one file of source code. Here's a succinct explanation of what each field does:
+
+* `Name string`: the name of the file, recorded in the span of every token parsed from it.
+* `Contents string`: the source code, with a leading UTF-8 byte order mark removed. All offsets into the file are offsets into `Contents`.
+* `base int`: the position of the first byte of the file within its `FileSet`.
+* `lines []int`: the offset of the first byte of every line, so offsets convert to lines with a binary search.
+
+Lines end with `\n`, `\r\n` or a lone `\r`, the same way the lexer counts them. A leading `#!`
+shebang line is left in `Contents` so line numbers stay true to the file; the lexer skips it.
+*/
+type SourceFile struct {
+	Name     string
+	Contents string
+	base     int
+	lines    []int
+}
+
+// utf8BOM Is the byte order mark some editors write at the start of UTF-8 files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+/*
+This function, `NewSourceFile`, creates a `SourceFile` that does not belong to any `FileSet`.
+It strips a leading byte order mark from `contents` and builds the line table.
+*/
+func NewSourceFile(name string, contents []byte) *SourceFile {
+	contents = bytes.TrimPrefix(contents, utf8BOM)
+
+	lines := []int{0}
+	for i, c := range contents {
+		if c == '\n' || (c == '\r' && (i+1 == len(contents) || contents[i+1] != '\n')) {
+			lines = append(lines, i+1)
+		}
+	}
+
+	return &SourceFile{Name: name, Contents: string(contents), lines: lines}
+}
+
+/*
+This method, `LineCount`, returns the number of lines in the file. A file that ends with a
+line ending has an empty last line.
+*/
+func (file *SourceFile) LineCount() int {
+	return len(file.lines)
+}
+
+/*
+This method, `LineStart`, returns the offset of the first byte of the given line, starting at 1.
+It returns -1 for lines outside the file.
+*/
+func (file *SourceFile) LineStart(line int) int {
+	if line < 1 || line > len(file.lines) {
+		return -1
+	}
+	return file.lines[line-1]
+}
+
+/*
+This method, `Position`, converts a byte offset into the file into a `lexer.Position` with the
+same line, rune column and UTF-16 column the lexer gives tokens. Offsets outside the file are
+clamped to its start or end.
+*/
+func (file *SourceFile) Position(offset int) lexer.Position {
+	offset = clamp(offset, 0, len(file.Contents))
+	line := sort.Search(len(file.lines), func(i int) bool { return file.lines[i] > offset })
+
+	// an invalid byte decodes as utf8.RuneError and counts as one column, like in the lexer
+	column, column16 := 1, 1
+	for _, r := range file.Contents[file.lines[line-1]:offset] {
+		column++
+		column16 += utf16.RuneLen(r)
+	}
+	return lexer.Position{Offset: offset, Line: line, Column: column, UTF16Column: column16}
+}
+
+/*
+This method, `Pos`, returns the position within the file's `FileSet` of a byte offset into the
+file. Positions from different files of the same set never overlap.
+*/
+func (file *SourceFile) Pos(offset int) int {
+	return file.base + offset
+}
+
+/*
+This class definition defines a struct called `FileSet` in Go, which holds every file read in one run.
+Here's a succinct explanation of what each field does:
+
+* `mutex sync.RWMutex`: guards the set so files can be added and looked up from several goroutines.
+* `files []*SourceFile`: the files in the order they were added.
+* `size int`: the position just past the last file, where the next file will start.
+
+Every file is given its own range of positions, so a single `int` identifies a byte in any file
+of the set, and `Position` turns it back into a file name, line and column.
+*/
+type FileSet struct {
+	mutex sync.RWMutex
+	files []*SourceFile
+	size  int
+}
+
+/*
+This function, `NewFileSet`, creates an empty `FileSet`. Position 0 is never used, so it can
+stand for "no position".
+*/
+func NewFileSet() *FileSet {
+	return &FileSet{size: 1}
+}
+
+/*
+This method, `AddFile`, adds a file with the given name and contents to the set and returns it.
+*/
+func (set *FileSet) AddFile(name string, contents []byte) *SourceFile {
+	file := NewSourceFile(name, contents)
+
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	file.base = set.size
+	set.size += len(file.Contents) + 1 // leave room for a position at the end of the file
+	set.files = append(set.files, file)
+	return file
+}
+
+/*
+This method, `ReadFile`, reads the named file from disk and adds it to the set.
+*/
+func (set *FileSet) ReadFile(name string) (*SourceFile, error) {
+	contents, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return set.AddFile(name, contents), nil
+}
+
+/*
+This method, `Files`, returns the files in the set, in the order they were added.
+*/
+func (set *FileSet) Files() []*SourceFile {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return append([]*SourceFile(nil), set.files...)
+}
+
+/*
+This method, `File`, returns the file containing the given position, or nil if no file does.
+*/
+func (set *FileSet) File(pos int) *SourceFile {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	i := sort.Search(len(set.files), func(i int) bool { return set.files[i].base > pos }) - 1
+	if i < 0 || pos > set.files[i].base+len(set.files[i].Contents) {
+		return nil
+	}
+	return set.files[i]
+}
+
+/*
+This method, `Position`, converts a position within the set into the name of the file it
+belongs to and the line and column within that file. It returns an empty name and an invalid
+position if no file contains the position.
+*/
+func (set *FileSet) Position(pos int) (string, lexer.Position) {
+	file := set.File(pos)
+	if file == nil {
+		return "", lexer.Position{}
+	}
+	return file.Name, file.Position(pos - file.base)
+}
+
+// clamp Returns value limited to the range min to max.
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
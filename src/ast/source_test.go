@@ -0,0 +1,157 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-parser/src/lexer"
+)
+
+// TestSourceFileBOM Checks that a leading byte order mark is removed and any other is kept.
+func TestSourceFileBOM(t *testing.T) {
+	tests := []struct {
+		contents string
+		want     string
+	}{
+		{"\xEF\xBB\xBFlet a", "let a"},
+		{"\xEF\xBB\xBF", ""},
+		{"let a", "let a"},
+		{"a\xEF\xBB\xBF", "a\xEF\xBB\xBF"},
+		{"\xEF\xBB\xBF\xEF\xBB\xBFa", "\xEF\xBB\xBFa"},
+	}
+
+	for _, test := range tests {
+		if file := NewSourceFile("a.lang", []byte(test.contents)); file.Contents != test.want {
+			t.Errorf("NewSourceFile(%q).Contents = %q, want %q", test.contents, file.Contents, test.want)
+		}
+	}
+}
+
+// TestSourceFileLines Checks the line table for every kind of line ending.
+func TestSourceFileLines(t *testing.T) {
+	tests := []struct {
+		contents string
+		starts   []int
+	}{
+		{"", []int{0}},
+		{"abc", []int{0}},
+		{"a\nb", []int{0, 2}},
+		{"a\nb\n", []int{0, 2, 4}},
+		{"a\r\nb\r\n", []int{0, 3, 6}},
+		{"a\rb\r", []int{0, 2, 4}},
+		{"\r\r\n\n", []int{0, 1, 3, 4}},
+		{"a\n\rb", []int{0, 2, 3}},
+		{"\xEF\xBB\xBFa\nb", []int{0, 2}},
+	}
+
+	for _, test := range tests {
+		file := NewSourceFile("a.lang", []byte(test.contents))
+		if file.LineCount() != len(test.starts) {
+			t.Errorf("%q has %d lines, want %d", test.contents, file.LineCount(), len(test.starts))
+			continue
+		}
+		for i, start := range test.starts {
+			if got := file.LineStart(i + 1); got != start {
+				t.Errorf("%q: line %d starts at %d, want %d", test.contents, i+1, got, start)
+			}
+		}
+		if file.LineStart(0) != -1 || file.LineStart(len(test.starts)+1) != -1 {
+			t.Errorf("%q: lines outside the file do not return -1", test.contents)
+		}
+	}
+}
+
+// TestSourceFilePosition Checks the line, rune column and UTF-16 column of offsets, including
+// multi-byte runes, runes outside the BMP, invalid bytes and offsets outside the file.
+func TestSourceFilePosition(t *testing.T) {
+	file := NewSourceFile("a.lang", []byte("aé😀b\r\nx\ry\xffz"))
+	tests := []struct {
+		offset int
+		want   lexer.Position
+	}{
+		{0, lexer.Position{Offset: 0, Line: 1, Column: 1, UTF16Column: 1}},
+		{1, lexer.Position{Offset: 1, Line: 1, Column: 2, UTF16Column: 2}},
+		{3, lexer.Position{Offset: 3, Line: 1, Column: 3, UTF16Column: 3}},
+		{7, lexer.Position{Offset: 7, Line: 1, Column: 4, UTF16Column: 5}},
+		{8, lexer.Position{Offset: 8, Line: 1, Column: 5, UTF16Column: 6}},
+		{10, lexer.Position{Offset: 10, Line: 2, Column: 1, UTF16Column: 1}},
+		{12, lexer.Position{Offset: 12, Line: 3, Column: 1, UTF16Column: 1}},
+		{14, lexer.Position{Offset: 14, Line: 3, Column: 3, UTF16Column: 3}},
+		{15, lexer.Position{Offset: 15, Line: 3, Column: 4, UTF16Column: 4}},
+		{-3, lexer.Position{Offset: 0, Line: 1, Column: 1, UTF16Column: 1}},
+		{100, lexer.Position{Offset: 15, Line: 3, Column: 4, UTF16Column: 4}},
+	}
+
+	for _, test := range tests {
+		if got := file.Position(test.offset); got != test.want {
+			t.Errorf("Position(%d) = %+v, want %+v", test.offset, got, test.want)
+		}
+	}
+}
+
+// TestSourceFileMatchesLexer Checks that Position agrees with the positions the lexer gives tokens.
+func TestSourceFileMatchesLexer(t *testing.T) {
+	source := "let é = \"😀\"\r\nconst b = é\rlet c = b\n"
+	file := NewSourceFile("a.lang", []byte(source))
+
+	tokens, err := lexer.Tokenize(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range tokens {
+		start := token.Span.Start
+		if got := file.Position(start.Offset); got != start {
+			t.Errorf("token %q starts at %+v, but Position gives %+v", token.Text, start, got)
+		}
+	}
+}
+
+// TestFileSet Checks that positions map back to the right file, line and column across several
+// files, including the position at the very end of each file.
+func TestFileSet(t *testing.T) {
+	set := NewFileSet()
+	a := set.AddFile("a.lang", []byte("ab\ncd"))
+	b := set.AddFile("b.lang", nil)
+	c := set.AddFile("c.lang", []byte("\xEF\xBB\xBFx\ny"))
+
+	if files := set.Files(); !reflect.DeepEqual(files, []*SourceFile{a, b, c}) {
+		t.Errorf("Files() = %v, want the files in the order they were added", files)
+	}
+
+	tests := []struct {
+		pos    int
+		file   *SourceFile
+		line   int
+		column int
+	}{
+		{0, nil, 0, 0},
+		{a.Pos(0), a, 1, 1},
+		{a.Pos(4), a, 2, 2},
+		{a.Pos(5), a, 2, 3},
+		{b.Pos(0), b, 1, 1},
+		{c.Pos(0), c, 1, 1},
+		{c.Pos(2), c, 2, 1},
+		{c.Pos(3), c, 2, 2},
+		{c.Pos(4), nil, 0, 0},
+		{-1, nil, 0, 0},
+	}
+
+	for _, test := range tests {
+		if file := set.File(test.pos); file != test.file {
+			t.Errorf("File(%d) = %v, want %v", test.pos, file, test.file)
+		}
+
+		name, position := set.Position(test.pos)
+		wantName := ""
+		if test.file != nil {
+			wantName = test.file.Name
+		}
+		if name != wantName || position.Line != test.line || position.Column != test.column {
+			t.Errorf("Position(%d) = %s %d:%d, want %s %d:%d", test.pos, name, position.Line, position.Column, wantName, test.line, test.column)
+		}
+	}
+
+	if a.Pos(len(a.Contents)) >= b.Pos(0) || b.Pos(len(b.Contents)) >= c.Pos(0) {
+		t.Errorf("file ranges overlap: a ends at %d, b is %d to %d, c starts at %d", a.Pos(len(a.Contents)), b.Pos(0), b.Pos(len(b.Contents)), c.Pos(0))
+	}
+}
@@ -1,5 +1,7 @@
 package ast
 
+import "github.com/go-parser/src/lexer"
+
 // { statement 1; statement 2; }
 /*
 
@@ -40,6 +42,7 @@ This class definition defines a `VarDeclStmt` struct in Go, which represents a v
 * `IsConstant`: indicates whether the variable is declared as a constant.
 * `AssignedValue`: stores the value assigned to the variable, if any.
 * `ExplicitType`: stores the explicit type of the variable, if any.
+* `Doc`: stores the `///` doc comment written above the declaration, if any.
 
 stmt() method:
 This method takes a VarDeclStmt receiver (v) and returns no value (i.e., it's a void function).
@@ -50,6 +53,32 @@ type VarDeclStmt struct {
 	IsConstant    bool
 	AssignedValue Expr
 	ExplicitType  Type
+	Doc           string
 }
 
 func (v VarDeclStmt) stmt() {}
+
+/*
+This class definition defines a struct called `ImportStmt` in Go, which represents an import statement. Here's a succinct explanation of what each field does:
+
+* `Name`: the name the imported module is bound to.
+* `From`: the path the module is imported from. It is the same as `Name` when the statement has no `from` clause.
+*/
+type ImportStmt struct {
+	Name string
+	From string
+}
+
+func (i ImportStmt) stmt() {}
+
+/*
+This class definition defines a struct called `BadStmt` in Go, which is a placeholder for a statement with a syntax error.
+The parser reports the error, skips to the next statement and leaves a `BadStmt` behind, so the rest of the tree is still usable.
+
+* `Span`: the region of source code that was skipped.
+*/
+type BadStmt struct {
+	Span lexer.Span
+}
+
+func (b BadStmt) stmt() {}
@@ -30,3 +30,29 @@ type ArrayType struct {
 }
 
 func (t ArrayType) _type() {}
+
+/*
+This class definition defines a struct called `QualifiedType` in Go, which represents a type named through a package or namespace, such as `fs.FileInfo`, in the abstract syntax tree (AST). Here's a succinct explanation of what each field does:
+
+* `Qualifier Type`: stores the type to the left of the dot, which is itself qualified for names such as `a.b.C`.
+* `Name string`: stores the name to the right of the dot.
+*/
+type QualifiedType struct {
+	Qualifier Type
+	Name      string
+}
+
+func (t QualifiedType) _type() {}
+
+/*
+This class definition defines a struct called `GenericType` in Go, which represents a generic type applied to type arguments, such as `Map<string, number>`, in the abstract syntax tree (AST). Here's a succinct explanation of what each field does:
+
+* `Base Type`: stores the generic type being applied, to the left of the angle brackets.
+* `Arguments []Type`: stores the type arguments in source order.
+*/
+type GenericType struct {
+	Base      Type
+	Arguments []Type
+}
+
+func (t GenericType) _type() {}
@@ -0,0 +1,85 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestComments Checks that line and block comments, nested ones included, are skipped, that
+// /// doc comments become DOC_COMMENT tokens, and that unterminated block comments are reported.
+func TestComments(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []string
+		err    string
+	}{
+		{"a // note\nb", []string{"a", "b"}, ""},
+		{"a // x /* y\nb", []string{"a", "b"}, ""},
+		{"a /* note */ b", []string{"a", "b"}, ""},
+		{"a /* x /* y */ z */ b", []string{"a", "b"}, ""},
+		{"a /* /* /* */ */ */ b", []string{"a", "b"}, ""},
+		{"/**/a", []string{"a"}, ""},
+		{"/* // */ b", []string{"b"}, ""},
+		{"/*/ b */ c", []string{"c"}, ""},
+		{"/* a", []string{}, "unterminated block comment"},
+		{"/* a /* b */", []string{}, "unterminated block comment"},
+
+		{"/// doc", []string{"doc_comment doc"}, ""},
+		{"///doc", []string{"doc_comment doc"}, ""},
+		{"///  indented", []string{"doc_comment  indented"}, ""},
+		{"///", []string{"doc_comment "}, ""},
+		{"//// not doc", []string{}, ""},
+		{"/////", []string{}, ""},
+		{"// / not doc", []string{}, ""},
+		{"/// one\n/// two\nlet", []string{"doc_comment one", "doc_comment two", "let"}, ""},
+		{"let a /// after\n", []string{"let", "a", "doc_comment after"}, ""},
+	}
+
+	for _, test := range tests {
+		tokens, errs := TokenizeWithErrors(test.source)
+		got := []string{}
+		for _, token := range tokens {
+			switch token.Kind {
+			case EOF, SEMI_COLON:
+			case DOC_COMMENT:
+				got = append(got, "doc_comment "+token.Value)
+			default:
+				got = append(got, token.Text)
+			}
+		}
+		if strings.Join(got, "|") != strings.Join(test.want, "|") {
+			t.Errorf("%q lexed as %q, want %q", test.source, got, test.want)
+		}
+
+		message := ""
+		if len(errs) > 0 {
+			message = errs[0].(*LexError).Message
+		}
+		if message != test.err || len(errs) > 1 {
+			t.Errorf("%q reported %v, want %q", test.source, errs, test.err)
+		}
+	}
+}
+
+// TestIsDocComment Checks that exactly three slashes start a doc comment.
+func TestIsDocComment(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    bool
+	}{
+		{"///", true},
+		{"/// doc", true},
+		{"///doc", true},
+		{"//", false},
+		{"// doc", false},
+		{"////", false},
+		{"//// doc", false},
+		{"// /", false},
+	}
+
+	for _, test := range tests {
+		if got := isDocComment(test.comment); got != test.want {
+			t.Errorf("isDocComment(%q) = %v, want %v", test.comment, got, test.want)
+		}
+	}
+}
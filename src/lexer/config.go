@@ -0,0 +1,207 @@
+package lexer
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"unicode/utf8"
+)
+
+/*
+This class definition defines a struct called `Config` in Go, which holds the tables a `Lexer` uses to recognise keywords and operators. Here's a succinct explanation of what each field does:
+
+* `keywords map[string]TokenKind`: maps the spelling of every keyword to its kind. Identifiers not in this map are emitted as IDENTIFIER.
+* `operators map[string]TokenKind`: maps the spelling of every operator and punctuation token to its kind.
+* `maxOperatorLength int`: stores the length in bytes of the longest spelling in `operators`.
+
+A `Config` is created with `DefaultConfig` and changed with `AddKeyword`, `RemoveKeyword`,
+`AddOperator` and `RemoveOperator`. Operators are always matched longest first, so adding
+=== keeps == and = working. A `Config` may be shared by any number of lexers, but it must not
+be changed while one of them is running.
+*/
+type Config struct {
+	keywords          map[string]TokenKind
+	operators         map[string]TokenKind
+	maxOperatorLength int
+}
+
+// defaultConfig Is the configuration used by NewLexer and the Tokenize functions.
+var defaultConfig = DefaultConfig()
+
+// DefaultConfig Returns a new configuration holding the keywords and operators of the language.
+// The result is a copy, so changing it does not affect other lexers.
+//
+// Return type: *Config
+func DefaultConfig() *Config {
+	config := &Config{
+		keywords:  make(map[string]TokenKind, len(isReservedKeyword)),
+		operators: make(map[string]TokenKind, len(operators)),
+	}
+	for spelling, kind := range isReservedKeyword {
+		config.keywords[spelling] = kind
+	}
+	for spelling, kind := range operators {
+		config.operators[spelling] = kind
+	}
+	config.updateMaxOperatorLength()
+	return config
+}
+
+// NewLexerWithConfig Creates a lexer like NewLexer that recognises the keywords and operators in config.
+//
+// filename - The name recorded in every token span, may be empty.
+// r - The reader the source code is read from.
+// config - The keyword and operator tables to use.
+// Return type: *Lexer
+func NewLexerWithConfig(filename string, r io.Reader, config *Config) *Lexer {
+	lex := NewLexer(filename, r)
+	lex.config = config
+	return lex
+}
+
+// AddKeyword Makes the identifier spelling lex as a token of the given kind.
+// An existing keyword with the same spelling is replaced.
+//
+// spelling - The keyword as written in source code, which must be a valid identifier.
+// kind - The kind of token emitted for the keyword.
+// Return type: error
+func (config *Config) AddKeyword(spelling string, kind TokenKind) error {
+	if !isIdentifier(spelling) {
+		return fmt.Errorf("keyword %q is not a valid identifier", spelling)
+	}
+	config.keywords[spelling] = kind
+	return nil
+}
+
+// RemoveKeyword Makes spelling lex as an ordinary IDENTIFIER again.
+//
+// spelling - The keyword to remove.
+// No return value.
+func (config *Config) RemoveKeyword(spelling string) {
+	delete(config.keywords, spelling)
+}
+
+// AddOperator Makes spelling lex as a token of the given kind.
+// An existing operator with the same spelling is replaced.
+//
+// An operator cannot start with a character that begins another kind of token:
+// whitespace, a digit, an identifier character, a quote, a backtick or a comment.
+//
+// spelling - The operator as written in source code.
+// kind - The kind of token emitted for the operator.
+// Return type: error
+func (config *Config) AddOperator(spelling string, kind TokenKind) error {
+	if spelling == "" {
+		return fmt.Errorf("operator spelling is empty")
+	}
+
+	first, _ := utf8.DecodeRuneInString(spelling)
+	switch {
+	case first == utf8.RuneError:
+		return fmt.Errorf("operator %q is not valid UTF-8", spelling)
+	case isSpace(first), isDigit(first), isIdentStart(first), first == '"', first == '`':
+		return fmt.Errorf("operator %q starts with %q, which begins another kind of token", spelling, first)
+	case len(spelling) >= 2 && (spelling[:2] == "//" || spelling[:2] == "/*"):
+		return fmt.Errorf("operator %q starts a comment", spelling)
+	}
+
+	config.operators[spelling] = kind
+	config.updateMaxOperatorLength()
+	return nil
+}
+
+// RemoveOperator Stops spelling from being recognised as an operator.
+// Longer operators that start with it are still recognised.
+//
+// spelling - The operator to remove.
+// No return value.
+func (config *Config) RemoveOperator(spelling string) {
+	delete(config.operators, spelling)
+	config.updateMaxOperatorLength()
+}
+
+// updateMaxOperatorLength Recomputes the length of the longest operator spelling.
+//
+// No return value.
+func (config *Config) updateMaxOperatorLength() {
+	config.maxOperatorLength = 0
+	for spelling := range config.operators {
+		if len(spelling) > config.maxOperatorLength {
+			config.maxOperatorLength = len(spelling)
+		}
+	}
+}
+
+// isIdentifier Reports whether s is spelled like an identifier.
+//
+// s - The text to check.
+// Return type: bool
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if r == utf8.RuneError || (i == 0 && !isIdentStart(r)) || !isIdentContinue(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// customKinds Stores the names of the token kinds added with RegisterTokenKind.
+// The kind of customKinds.names[i] is NUM_TOKENS + i.
+var customKinds struct {
+	sync.RWMutex
+	names []string
+}
+
+// RegisterTokenKind Allocates a new token kind for a dialect keyword or operator.
+// The kind is numbered after NUM_TOKENS, and TokenKindString returns name for it.
+// Registering a name that is already taken, including a built-in name, returns the existing kind.
+//
+// name - The name of the new kind, such as "match".
+// Return type: TokenKind
+func RegisterTokenKind(name string) TokenKind {
+	if kind, err := ParseTokenKind(name); err == nil {
+		return kind
+	}
+
+	customKinds.Lock()
+	defer customKinds.Unlock()
+
+	for i, existing := range customKinds.names {
+		if existing == name {
+			return NUM_TOKENS + TokenKind(i)
+		}
+	}
+	customKinds.names = append(customKinds.names, name)
+	return NUM_TOKENS + TokenKind(len(customKinds.names)-1)
+}
+
+// customKindByName Returns the kind added with RegisterTokenKind under the given name.
+//
+// name - The name to look up.
+// Return type: TokenKind, bool (whether the name was registered)
+func customKindByName(name string) (TokenKind, bool) {
+	customKinds.RLock()
+	defer customKinds.RUnlock()
+
+	for i, existing := range customKinds.names {
+		if existing == name {
+			return NUM_TOKENS + TokenKind(i), true
+		}
+	}
+	return ILLEGAL, false
+}
+
+// customKindName Returns the name of a kind added with RegisterTokenKind.
+//
+// kind - The kind to look up.
+// Return type: string, bool (whether the kind was registered)
+func customKindName(kind TokenKind) (string, bool) {
+	customKinds.RLock()
+	defer customKinds.RUnlock()
+
+	i := int(kind - NUM_TOKENS)
+	if i < 0 || i >= len(customKinds.names) {
+		return "", false
+	}
+	return customKinds.names[i], true
+}
@@ -0,0 +1,136 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+// lexKinds Lexes source with config and returns the kinds of its tokens, without the trailing
+// implicit semicolon and EOF.
+func lexKinds(t *testing.T, source string, config *Config) []TokenKind {
+	t.Helper()
+	lex := NewLexerWithConfig("", strings.NewReader(source), config)
+	kinds := []TokenKind{}
+	for token := lex.Next(); token.Kind != EOF; token = lex.Next() {
+		if !token.Is(IMPLICIT) {
+			kinds = append(kinds, token.Kind)
+		}
+	}
+	if errs := lex.Errors(); len(errs) > 0 {
+		t.Errorf("lexing %q failed: %v", source, errs)
+	}
+	return kinds
+}
+
+// checkKinds Fails the test unless source lexes with config to exactly the kinds in want.
+func checkKinds(t *testing.T, source string, config *Config, want ...TokenKind) {
+	t.Helper()
+	got := lexKinds(t, source, config)
+	if len(got) != len(want) {
+		t.Errorf("%q lexed as %v, want %v", source, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%q lexed as %v, want %v", source, got, want)
+			return
+		}
+	}
+}
+
+// TestConfigLongestOperator Checks that adding === keeps == and = working, since operators match longest first.
+func TestConfigLongestOperator(t *testing.T) {
+	strictEquals := RegisterTokenKind("strict_equals")
+	config := DefaultConfig()
+	if err := config.AddOperator("===", strictEquals); err != nil {
+		t.Fatal(err)
+	}
+
+	checkKinds(t, "a === b == c = d", config, IDENTIFIER, strictEquals, IDENTIFIER, EQUALS, IDENTIFIER, ASSIGNMENT, IDENTIFIER)
+	checkKinds(t, "a ==== b", config, IDENTIFIER, strictEquals, ASSIGNMENT, IDENTIFIER)
+
+	config.RemoveOperator("==")
+	checkKinds(t, "a === b == c", config, IDENTIFIER, strictEquals, IDENTIFIER, ASSIGNMENT, ASSIGNMENT, IDENTIFIER)
+}
+
+// TestConfigKeywords Checks adding and removing keywords, including contextual and custom ones.
+func TestConfigKeywords(t *testing.T) {
+	match := RegisterTokenKind("match")
+	config := DefaultConfig()
+	if err := config.AddKeyword("match", match); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.AddKeyword("from", FROM); err != nil {
+		t.Fatal(err)
+	}
+	config.RemoveKeyword("let")
+
+	checkKinds(t, "match let from in", config, match, IDENTIFIER, FROM, IDENTIFIER)
+	checkKinds(t, "match let from in", DefaultConfig(), IDENTIFIER, LET, IDENTIFIER, IDENTIFIER)
+
+	for _, spelling := range []string{"", "1abc", "a-b", "a b", "\xff"} {
+		if err := config.AddKeyword(spelling, match); err == nil {
+			t.Errorf("AddKeyword(%q) accepted an invalid identifier", spelling)
+		}
+	}
+}
+
+// TestConfigRejectsOperators Checks that operators which could never match, or would hide other tokens, are rejected.
+func TestConfigRejectsOperators(t *testing.T) {
+	config := DefaultConfig()
+	for _, spelling := range []string{"", "\xff", " +", "1+", "a+", "é+", "\"+", "`", "`x", "//", "/*x"} {
+		if err := config.AddOperator(spelling, PLUS); err == nil {
+			t.Errorf("AddOperator(%q) accepted an invalid operator", spelling)
+		}
+	}
+	for _, spelling := range []string{"->", "|>", "@", "/=", "≠"} {
+		if err := config.AddOperator(spelling, PLUS); err != nil {
+			t.Errorf("AddOperator(%q) failed: %v", spelling, err)
+		}
+	}
+	checkKinds(t, "a |> b ≠ c", config, IDENTIFIER, PLUS, IDENTIFIER, PLUS, IDENTIFIER)
+}
+
+// TestDefaultConfigIsIndependent Checks that DefaultConfig returns a fresh copy every time, so
+// changing one configuration leaves other configurations and NewLexer untouched.
+func TestDefaultConfigIsIndependent(t *testing.T) {
+	config := DefaultConfig()
+	config.RemoveKeyword("let")
+	config.RemoveOperator("+")
+	if err := config.AddOperator("<=>", LESS); err != nil {
+		t.Fatal(err)
+	}
+
+	checkKinds(t, "let a + b", DefaultConfig(), LET, IDENTIFIER, PLUS, IDENTIFIER)
+	tokens, err := Tokenize("let a + b <=> c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens[0].Kind != LET || tokens[2].Kind != PLUS || tokens[4].Kind != LESS_EQUALS || tokens[5].Kind != GREATER {
+		t.Errorf("changing a config changed the default lexer: %v", tokens)
+	}
+}
+
+// TestRegisterTokenKind Checks that custom kinds get new numbers and names, and that names are never registered twice.
+func TestRegisterTokenKind(t *testing.T) {
+	pipe := RegisterTokenKind("test_pipe")
+	if pipe < NUM_TOKENS {
+		t.Errorf("custom kind %d overlaps the built-in kinds", int(pipe))
+	}
+	if again := RegisterTokenKind("test_pipe"); again != pipe {
+		t.Errorf("registering test_pipe twice returned %d and %d", int(pipe), int(again))
+	}
+	if builtin := RegisterTokenKind("plus"); builtin != PLUS {
+		t.Errorf("registering the built-in name plus returned %d", int(builtin))
+	}
+	if other := RegisterTokenKind("test_other"); other == pipe {
+		t.Error("two names were given the same kind")
+	}
+
+	if pipe.String() != "test_pipe" {
+		t.Errorf("custom kind is named %q", pipe.String())
+	}
+	if parsed, err := ParseTokenKind("test_pipe"); err != nil || parsed != pipe {
+		t.Errorf("ParseTokenKind(test_pipe) = %d, %v", int(parsed), err)
+	}
+}
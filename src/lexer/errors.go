@@ -0,0 +1,28 @@
+package lexer
+
+import "fmt"
+
+/*
+This class definition defines a struct called `LexError` in Go, which describes a problem found while lexing. Here's a succinct explanation of what each field does:
+
+* `Span Span`: the region of source code the error refers to.
+* `Text string`: the offending source text.
+* `Message string`: a short, human readable description of the problem.
+
+`LexError` implements the `error` interface, so it can be returned wherever an error is expected.
+*/
+type LexError struct {
+	Span    Span
+	Text    string
+	Message string
+}
+
+// Error Returns the error formatted as file:line:column: message.
+//
+// Return type: string
+func (err *LexError) Error() string {
+	if err.Text == "" {
+		return fmt.Sprintf("%s: %s", err.Span, err.Message)
+	}
+	return fmt.Sprintf("%s: %s %q", err.Span, err.Message, err.Text)
+}
@@ -0,0 +1,54 @@
+package lexer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// addExampleSeeds Adds every program in examples/ to the fuzzer's seed corpus.
+func addExampleSeeds(f *testing.F) {
+	paths, err := filepath.Glob("../../examples/*.lang")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		example, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(example))
+	}
+}
+
+// FuzzTokenize Checks that no input makes the lexer panic, that every token's Text is the
+// source it spans, and that trivia mode reproduces the source byte for byte.
+func FuzzTokenize(f *testing.F) {
+	addExampleSeeds(f)
+	f.Add("\"a ${ {b} } ${\"c${d}\"}\" /* /* */ 0x_ 1e+ \\u{110000} \xff é")
+	f.Add("\uFEFF#!/bin/lang\r\nlet a = 1\rlet b = 2\r\n")
+	f.Add("let q = ```\n    SELECT *\r\n\t FROM t\n    ```\nlet r = `C:\\dir\n`")
+
+	f.Fuzz(func(t *testing.T, source string) {
+		tokens, _ := TokenizeWithErrors(source)
+		if len(tokens) == 0 || tokens[len(tokens)-1].Kind != EOF {
+			t.Fatalf("token list does not end with EOF: %v", tokens)
+		}
+		for _, token := range tokens {
+			start, end := token.Span.Start.Offset, token.Span.End.Offset
+			if start < 0 || start > end || end > len(source) || source[start:end] != token.Text {
+				t.Fatalf("token %v does not match the source it spans", token)
+			}
+		}
+
+		tokens, _ = TokenizeWithTrivia(source)
+		var text strings.Builder
+		for _, token := range tokens {
+			text.WriteString(token.FullText())
+		}
+		if text.String() != source {
+			t.Fatalf("trivia round trip gave %q", text.String())
+		}
+	})
+}
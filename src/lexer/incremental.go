@@ -0,0 +1,278 @@
+package lexer
+
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+The `Edit` struct in Go describes a change made to source code, as reported by an editor. It has three fields:
+
+- `Start`: the byte offset in the old source where the replaced text begins.
+- `End`: the byte offset in the old source just past the replaced text. It equals `Start` for an insertion.
+- `Text`: the text that replaces the old source between `Start` and `End`. It is empty for a deletion.
+*/
+type Edit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// Apply Returns source with the edit applied.
+//
+// source - The source code the edit was made against.
+// Return type: string
+func (edit Edit) Apply(source string) string {
+	return source[:edit.Start] + edit.Text + source[edit.End:]
+}
+
+/*
+The `TokenStream` struct in Go holds the result of lexing a piece of source code, so it can be
+updated cheaply with `Relex` when the source is edited. It has four fields:
+
+- `Source`: stores the source code that was lexed.
+- `Tokens`: stores the tokens of the source code, ending with an EOF token.
+- `Errors`: stores the lexing errors, in source order.
+- `errorCounts`: stores, for every token, how many errors had been reported once that token was scanned.
+
+A `TokenStream` always holds exactly what `TokenizeWithErrors` returns for `Source`.
+*/
+type TokenStream struct {
+	Source      string
+	Tokens      []Token
+	Errors      []error
+	errorCounts []int
+}
+
+// NewTokenStream Lexes the whole source code into a token stream that can later be relexed incrementally.
+//
+// source - The source code to be lexed.
+// Return type: TokenStream
+func NewTokenStream(source string) TokenStream {
+	lex := createLexer([]byte(source))
+	stream := TokenStream{Source: source}
+	stream.lexFrom(lex, nil, 0)
+	return stream
+}
+
+// Relex Applies an edit to the stream's source code and returns the updated stream.
+//
+// Only the damaged region is lexed again. Lexing restarts after the last token that ends on a line
+// before the edit, and stops as soon as the lexer reaches the end of an old token that lies on a line
+// after the edit with the same lexer state, because from there on the old tokens are still valid and
+// only need their positions moved. The result is always the same as lexing the new source from scratch.
+// The unchanged tokens are still copied into the new stream, but that is far cheaper than scanning them.
+//
+// edit - The change made to the source code, with offsets into the stream's source.
+// Return type: TokenStream, error (when the edit does not fit the source)
+func (stream TokenStream) Relex(edit Edit) (TokenStream, error) {
+	if edit.Start < 0 || edit.Start > edit.End || edit.End > len(stream.Source) {
+		return stream, fmt.Errorf("edit %d:%d is outside the source of length %d", edit.Start, edit.End, len(stream.Source))
+	}
+
+	source := edit.Apply(stream.Source)
+	restart := stream.restartIndex(edit)
+
+	// set the lexer up exactly as it was after scanning the last kept token
+	lex := createLexer([]byte(source))
+	errorCount := 0
+	if restart >= 0 {
+		end := stream.Tokens[restart].Span.End
+		lex.pos, lex.line, lex.column, lex.column16 = end.Offset, end.Line, end.Column, end.UTF16Column
+		state := replayState(lexState{}, stream.Tokens[:restart+1])
+		lex.templates = state.templates
+		lex.insertSemi = endsStatement(stream.Tokens[restart].Kind)
+		errorCount = stream.errorCounts[restart]
+	}
+
+	// an edit rarely changes the number of tokens by much, so room for the old count avoids regrowing
+	updated := TokenStream{
+		Source:      source,
+		Tokens:      append(make([]Token, 0, len(stream.Tokens)+16), stream.Tokens[:restart+1]...),
+		Errors:      append([]error(nil), stream.Errors[:errorCount]...),
+		errorCounts: append(make([]int, 0, len(stream.Tokens)+16), stream.errorCounts[:restart+1]...),
+	}
+	updated.lexFrom(lex, &stream, resyncLine(source, edit))
+	return updated, nil
+}
+
+// restartIndex Returns the index of the last token that is not affected by the edit, or -1 if every token is.
+//
+// A token ending on an earlier line than the edit was scanned without looking at the edited text,
+// because no token, and no lookahead used to choose one, reaches past a newline.
+//
+// edit - The change made to the source code.
+// Return type: int
+func (stream TokenStream) restartIndex(edit Edit) int {
+	line := 1 + CountLines(stream.Source[:edit.Start])
+	return sort.Search(len(stream.Tokens), func(i int) bool {
+		return stream.Tokens[i].Span.End.Line >= line
+	}) - 1
+}
+
+// resyncLine Returns the first line of the new source that comes entirely after the edited text.
+//
+// source - The source code with the edit applied.
+// edit - The change made to the source code.
+// Return type: int
+func resyncLine(source string, edit Edit) int {
+	return 2 + CountLines(source[:edit.Start+len(edit.Text)])
+}
+
+// lexFrom Appends the tokens scanned by lex to the stream until EOF. When old is not nil, it stops
+// early once it reaches a token on or after line resync that ends where an old token ends, with the
+// same lexer state, and appends the rest of the old tokens and errors with their positions moved.
+//
+// lex - The lexer to read tokens from.
+// old - The stream before the edit, or nil to lex everything.
+// resync - The first line where the old tokens can be reused.
+// No return value.
+func (stream *TokenStream) lexFrom(lex *Lexer, old *TokenStream, resync int) {
+	shift := len(stream.Source)
+	if old != nil {
+		shift -= len(old.Source)
+	}
+
+	reported := 0
+	replayed, oldState := 0, lexState{}
+	for {
+		token := lex.Next()
+		stream.Tokens = append(stream.Tokens, token)
+		stream.Errors = append(stream.Errors, lex.Errors()[reported:]...)
+		reported = len(lex.Errors())
+		stream.errorCounts = append(stream.errorCounts, len(stream.Errors))
+		if token.Kind == EOF {
+			return
+		}
+
+		if old == nil || token.Span.End.Line < resync || len(lex.queue) > 0 {
+			continue
+		}
+		j := old.tokenEndingAt(token.Span.End.Offset - shift)
+		if j < 0 || !sameToken(old.Tokens[j], token) {
+			continue
+		}
+		// candidates only move forward, so the old state is replayed once overall
+		oldState, replayed = replayState(oldState, old.Tokens[replayed:j+1]), j+1
+		if oldState.equal(lexState{lex.templates}) {
+			stream.splice(old, j, shift, token.Span.End.Line-old.Tokens[j].Span.End.Line)
+			return
+		}
+	}
+}
+
+// splice Appends the old tokens and errors after old token j, moved by shift bytes and lines lines.
+//
+// old - The stream before the edit.
+// j - The index of the last old token that was scanned again.
+// shift - How many bytes the source grew by.
+// lines - How many lines the source grew by.
+// No return value.
+func (stream *TokenStream) splice(old *TokenStream, j, shift, lines int) {
+	move := func(span Span) Span {
+		span.Start.Offset += shift
+		span.Start.Line += lines
+		span.End.Offset += shift
+		span.End.Line += lines
+		return span
+	}
+
+	errorShift := len(stream.Errors) - old.errorCounts[j]
+	for _, err := range old.Errors[old.errorCounts[j]:] {
+		if lexErr, ok := err.(*LexError); ok {
+			moved := *lexErr
+			moved.Span = move(moved.Span)
+			err = &moved
+		}
+		stream.Errors = append(stream.Errors, err)
+	}
+
+	for i, token := range old.Tokens[j+1:] {
+		token.Span = move(token.Span)
+		stream.Tokens = append(stream.Tokens, token)
+		stream.errorCounts = append(stream.errorCounts, old.errorCounts[j+1+i]+errorShift)
+	}
+}
+
+// tokenEndingAt Returns the index of the token ending at offset, or -1 if there is none.
+//
+// offset - The byte offset in the stream's source.
+// Return type: int
+func (stream TokenStream) tokenEndingAt(offset int) int {
+	i := sort.Search(len(stream.Tokens), func(i int) bool {
+		return stream.Tokens[i].Span.End.Offset >= offset
+	})
+	if i < len(stream.Tokens) && stream.Tokens[i].Span.End.Offset == offset && stream.Tokens[i].Kind != EOF {
+		return i
+	}
+	return -1
+}
+
+// sameToken Reports whether two tokens have the same kind, value and text.
+//
+// a - The first token.
+// b - The second token.
+// Return type: bool
+func sameToken(a, b Token) bool {
+	return a.Kind == b.Kind && a.Value == b.Value && a.Text == b.Text
+}
+
+/*
+The `lexState` struct in Go holds the part of the lexer's state that depends on the tokens scanned
+before the current position. Two lexers with the same state at the same position of the same text
+scan the same tokens from there on. It has one field:
+
+- `templates`: the brace counts of the open template expressions, as in `Lexer.templates`.
+
+Whether a semicolon may be inserted also depends on the kind of the last token, which both lexers share.
+*/
+type lexState struct {
+	templates []int
+}
+
+// replayState Returns the state the lexer has after scanning tokens, starting from the given
+// state, following the same rules as templateHandler and countBraces.
+//
+// state - The state before the tokens, which is updated in place.
+// tokens - The tokens scanned after that state.
+// Return type: lexState
+func replayState(state lexState, tokens []Token) lexState {
+	for _, token := range tokens {
+		switch {
+		case token.Kind == TEMPLATE_HEAD:
+			state.templates = append(state.templates, 0)
+		case token.Kind == TEMPLATE_TAIL && len(state.templates) > 0:
+			state.templates = state.templates[:len(state.templates)-1]
+		case token.Kind == OPEN_CURLY && len(state.templates) > 0:
+			state.templates[len(state.templates)-1]++
+		case token.Kind == CLOSE_CURLY && len(state.templates) > 0:
+			state.templates[len(state.templates)-1]--
+		}
+	}
+	return state
+}
+
+// equal Reports whether two lexer states are the same.
+//
+// other - The state to compare with.
+// Return type: bool
+func (state lexState) equal(other lexState) bool {
+	return equalTemplates(state.templates, other.templates)
+}
+
+// equalTemplates Reports whether two template states are the same.
+//
+// a - The first state.
+// b - The second state.
+// Return type: bool
+func equalTemplates(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
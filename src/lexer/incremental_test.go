@@ -0,0 +1,90 @@
+package lexer
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// relexSources Returns the example programs plus snippets that stress multi-line and stateful tokens.
+func relexSources(t *testing.T) []string {
+	sources := []string{
+		"let a = \"hello ${name} and ${ {x: 1} }!\";\nlet b = 2;\n",
+		"/* block\n comment */ let x = 1;\n/// doc\nconst y = \"unterminated\nlet z = 0x_1;\n",
+		"let s = \"${a +\n b}\";\nfoo(1.5e3, 0b101);\n",
+		"",
+	}
+
+	paths, err := filepath.Glob("../../examples/*.lang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		example, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, string(example))
+	}
+	return sources
+}
+
+// relexInsertions Are the fragments inserted by TestRelexMatchesFullLex, chosen to open and close
+// strings, templates, comments and braces, and to join or split neighbouring tokens.
+var relexInsertions = []string{
+	"", "a", "1", ".", "=", "\"", "${", "}", "{", "/*", "*/", "//", "///", "\n", " ", "\\", "\n\n", "é", "\xff", "0x",
+	"\r", "\r\n", "#!", "\uFEFF", "`", "```", "...",
+}
+
+// TestRelexMatchesFullLex Applies thousands of random edits and checks that every relexed
+// stream is identical to lexing the edited source from scratch.
+func TestRelexMatchesFullLex(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for _, source := range relexSources(t) {
+		stream := NewTokenStream(source)
+		for i := 0; i < 300; i++ {
+			start := random.Intn(len(stream.Source) + 1)
+			end := start + random.Intn(min(4, len(stream.Source)-start)+1)
+			edit := Edit{Start: start, End: end, Text: relexInsertions[random.Intn(len(relexInsertions))]}
+
+			relexed, err := stream.Relex(edit)
+			if err != nil {
+				t.Fatalf("Relex(%+v) failed: %v", edit, err)
+			}
+			checkStream(t, relexed, edit)
+			stream = relexed
+		}
+	}
+}
+
+// TestRelexRejectsInvalidEdits Checks that edits outside the source are reported instead of applied.
+func TestRelexRejectsInvalidEdits(t *testing.T) {
+	stream := NewTokenStream("let a = 1;")
+	for _, edit := range []Edit{{Start: -1, End: 0}, {Start: 3, End: 2}, {Start: 0, End: 11}} {
+		if _, err := stream.Relex(edit); err == nil {
+			t.Errorf("Relex(%+v) accepted an invalid edit", edit)
+		}
+	}
+}
+
+// checkStream Fails the test unless stream holds exactly what a full lex of its source returns.
+func checkStream(t *testing.T, stream TokenStream, edit Edit) {
+	t.Helper()
+	tokens, errs := TokenizeWithErrors(stream.Source)
+	if !reflect.DeepEqual(stream.Tokens, tokens) {
+		t.Fatalf("after %+v the tokens of %q differ from a full lex:\n got %v\nwant %v", edit, stream.Source, stream.Tokens, tokens)
+	}
+	if fmt.Sprint(stream.Errors) != fmt.Sprint(errs) {
+		t.Fatalf("after %+v the errors of %q differ from a full lex:\n got %v\nwant %v", edit, stream.Source, stream.Errors, errs)
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
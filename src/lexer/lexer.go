@@ -2,236 +2,1104 @@ package lexer
 
 import (
 	"fmt"
-	"regexp"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 /*
-This line of code defines a type alias in Go called `regexHandler`. It represents a function that takes two parameters: a pointer to a `lexer` struct (`lex *lexer`) and a pointer to a `regexp.Regexp` object (`regex *regexp.Regexp`).
+This class definition defines a struct called `Lexer` in Go. Here's a succinct explanation of what each field does:
+
+* `queue []Token`: stores the tokens that have been scanned but not yet handed out by `Next`.
+* `errors []error`: stores the errors found while lexing, in source order.
+* `file string`: stores the name of the file being lexed, copied into every token span.
+* `reader io.Reader`: stores where more source code is read from, nil once everything has been read.
+* `buf []byte`: stores the part of the source code that has been read but not yet discarded.
+* `base int`: stores the offset in the source code of the first byte in `buf`.
+* `pos int`: stores the current offset in the source code.
+* `line int`: stores the line number of the current position, starting at 1.
+* `column int`: stores the column number of the current position in runes, starting at 1.
+* `column16 int`: stores the column number of the current position in UTF-16 code units, starting at 1.
+* `afterCR bool`: stores whether the last character was a \r, so the \n of a \r\n line ending does not count as another line.
+* `keepTrivia bool`: stores whether whitespace and comments are kept as token trivia.
+* `trivia []Trivia`: stores the trivia scanned since the last token, waiting to be attached to a token.
+* `templates []int`: stores one entry per template string whose ${ expression is being lexed, counting the { braces opened inside that expression.
+* `config *Config`: stores the keyword and operator tables used to recognise tokens.
+* `insertSemi bool`: stores whether the last token can end a statement, so a newline after it inserts a semicolon.
+
+The lexer is a hand-written scanner: it looks at the current character to decide which handler
+to run, and every handler only ever moves forward, so lexing is linear in the size of the source.
+Source code is read in chunks and discarded once the tokens covering it have been scanned, so
+memory use is bounded by the longest token rather than the size of the input.
 */
-type regexHandler func(lex *lexer, regex *regexp.Regexp)
+type Lexer struct {
+	queue      []Token
+	errors     []error
+	file       string
+	reader     io.Reader
+	buf        []byte
+	base       int
+	pos        int
+	line       int
+	column     int
+	column16   int
+	afterCR    bool
+	keepTrivia bool
+	trivia     []Trivia
+	templates  []int
+	config     *Config
+	insertSemi bool
+}
 
-/*
-This class definition defines a struct called `regexPattern` in Go. Here's a succinct explanation of what each field does:
+// readChunkSize Is the number of bytes requested from the reader at a time.
+const readChunkSize = 32 << 10
 
-* `regex *regexp.Regexp`: This field stores a compiled regular expression that can be used to match patterns in text.
-* `handler regexHandler`: This field stores a function that takes a `lexer` and a `regexp.Regexp` as parameters, and is used to handle matches found by the regular expression.
+// NewLexer Creates a lexer that reads source code from r and hands out tokens one at a time.
+//
+// filename - The name recorded in every token span, may be empty.
+// r - The reader the source code is read from.
+// Return type: *Lexer
+func NewLexer(filename string, r io.Reader) *Lexer {
+	lex := createLexer(nil)
+	lex.file = filename
+	lex.reader = r
+	return lex
+}
 
-Note that this struct does not have any methods, it only has fields to store a regular expression and a handler function.
-*/
-type regexPattern struct {
-	regex   *regexp.Regexp
-	handler regexHandler
+// Next Returns the next token and advances past it.
+// Once the source code is exhausted every call returns an EOF token.
+//
+// Return type: Token
+func (lex *Lexer) Next() Token {
+	token := lex.Peek()
+	if token.Kind != EOF {
+		lex.queue = append(lex.queue[:0], lex.queue[1:]...)
+	}
+	return token
 }
 
-/*
-This class definition defines a struct called `lexer` in Go. Here's a succinct explanation of what each field does:
+// Peek Returns the next token without advancing past it.
+//
+// Return type: Token
+func (lex *Lexer) Peek() Token {
+	for len(lex.queue) == 0 {
+		lex.discard()
+		if lex.at_eof() {
+			if lex.semiAllowed() {
+				lex.insertSemicolon()
+				break
+			}
+			if len(lex.templates) > 0 {
+				lex.error(lex.spanFrom(lex.position()), "", "unterminated template string, expected } to close ${")
+				lex.templates = nil
+			}
+			lex.push(NewTokenAt(EOF, "eof", lex.spanFrom(lex.position())))
+			break
+		}
+		lex.scanToken()
+		if lex.keepTrivia && len(lex.queue) > 0 {
+			lex.scanTrailingTrivia()
+		}
+	}
+	return lex.queue[0]
+}
 
-* `Tokens []Token`: stores a list of tokens found in the source code.
-* `source string`: stores the source code being lexed.
-* `pos int`: stores the current position in the source code.
-* `patterns []regexPattern`: stores a list of regular expression patterns used to match tokens in the source code.
-*/
-type lexer struct {
-	Tokens   []Token
-	source   string
-	pos      int
-	patterns []regexPattern
+// Errors Returns the errors found so far, in source order.
+//
+// Return type: []error
+func (lex *Lexer) Errors() []error {
+	return lex.errors
 }
 
-// advanceN Advances the lexer's position by a specified number of characters.
+// discard Drops the source code before the current position from the buffer.
+// It is only called between tokens, so no token still refers to the dropped bytes.
 //
-// n - The number of characters to advance the lexer's position.
 // No return value.
-func (lex *lexer) advanceN(n int) {
+func (lex *Lexer) discard() {
+	if lex.reader == nil || lex.pos-lex.base < readChunkSize {
+		return
+	}
+	n := copy(lex.buf, lex.buf[lex.pos-lex.base:])
+	lex.buf = lex.buf[:n]
+	lex.base = lex.pos
+}
+
+// fill Reads another chunk of source code into the buffer.
+// Read errors other than io.EOF are reported as lexing errors and end the input.
+//
+// Return type: bool (whether any bytes were read)
+func (lex *Lexer) fill() bool {
+	for lex.reader != nil {
+		if cap(lex.buf)-len(lex.buf) < readChunkSize {
+			grown := make([]byte, len(lex.buf), 2*cap(lex.buf)+readChunkSize)
+			copy(grown, lex.buf)
+			lex.buf = grown
+		}
+
+		n, err := lex.reader.Read(lex.buf[len(lex.buf):cap(lex.buf)])
+		lex.buf = lex.buf[:len(lex.buf)+n]
+		if err != nil {
+			if err != io.EOF {
+				lex.error(lex.spanFrom(lex.position()), "", "read error: "+err.Error())
+			}
+			lex.reader = nil
+		}
+		if n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ensure Makes sure the byte n bytes past the current position has been read, if it exists.
+//
+// n - The distance from the current position.
+// Return type: bool (whether the byte exists)
+func (lex *Lexer) ensure(n int) bool {
+	for lex.pos-lex.base+n >= len(lex.buf) {
+		if !lex.fill() {
+			return false
+		}
+	}
+	return true
+}
+
+// advanceN Advances the lexer's position by a specified number of bytes.
+// The line and column are kept in step with the runes that were skipped; every byte of
+// invalid UTF-8 counts as one column.
+//
+// n - The number of bytes to advance the lexer's position.
+// No return value.
+func (lex *Lexer) advanceN(n int) {
+	start := lex.pos - lex.base
+	for text := lex.buf[start : start+n]; len(text) > 0; {
+		r, size := rune(text[0]), 1
+		if r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRune(text)
+		}
+		text = text[size:]
+
+		switch {
+		case r == '\n' && lex.afterCR:
+			// the second half of a \r\n line ending
+		case isNewline(r):
+			lex.line++
+			lex.column = 1
+			lex.column16 = 1
+		default:
+			lex.column++
+			lex.column16 += utf16.RuneLen(r)
+		}
+		lex.afterCR = r == '\r'
+	}
 	lex.pos += n
 }
 
-// push Appends a token to the lexer's token list.
+// advance Advances the lexer's position past the rune at the current position.
+//
+// No return value.
+func (lex *Lexer) advance() {
+	_, size := lex.atRune()
+	lex.advanceN(size)
+}
+
+// position Returns the position of the lexer in the source code.
+//
+// Return type: Position
+func (lex *Lexer) position() Position {
+	return Position{Offset: lex.pos, Line: lex.line, Column: lex.column, UTF16Column: lex.column16}
+}
+
+// spanFrom Returns the span between the given start position and the lexer's current position.
+//
+// start - The position where the span begins.
+// Return type: Span
+func (lex *Lexer) spanFrom(start Position) Span {
+	return Span{File: lex.file, Start: start, End: lex.position()}
+}
+
+// textFrom Returns the source code between the given offset and the lexer's current position.
+//
+// offset - The offset where the text begins, which must not have been discarded.
+// Return type: string
+func (lex *Lexer) textFrom(offset int) string {
+	return string(lex.buf[offset-lex.base : lex.pos-lex.base])
+}
+
+// push Appends a token to the queue of tokens waiting to be handed out.
+// The token's Text is filled in with the source code covered by its span, and any
+// pending trivia becomes its leading trivia.
 //
-// token - The token to be appended to the lexer's token list.
+// token - The token to be appended to the queue.
 // No return value.
-func (lex *lexer) push(token Token) {
-	lex.Tokens = append(lex.Tokens, token)
+func (lex *Lexer) push(token Token) {
+	token.Text = lex.textFrom(token.Span.Start.Offset)
+	token.Leading = lex.trivia
+	lex.trivia = nil
+	lex.queue = append(lex.queue, token)
+	lex.insertSemi = endsStatement(token.Kind)
 }
 
-// at Returns the character at the current position in the lexer's source code.
+// error Records a lexing error covering the given span.
+//
+// span - The region of source code the error refers to.
+// text - The offending source text.
+// message - A short description of the problem.
+// No return value.
+func (lex *Lexer) error(span Span, text string, message string) {
+	lex.errors = append(lex.errors, &LexError{Span: span, Text: text, Message: message})
+}
+
+// at Returns the rune at the current position in the lexer's source code.
+// Invalid UTF-8 is returned as utf8.RuneError.
 //
 // lex - The lexer instance.
+// Return type: rune
+func (lex *Lexer) at() rune {
+	r, _ := lex.atRune()
+	return r
+}
+
+// atRune Decodes the rune at the current position in the lexer's source code.
+// Invalid UTF-8 is returned as utf8.RuneError with a size of 1.
+//
+// Return type: rune, int (the size of the rune in bytes)
+func (lex *Lexer) atRune() (rune, int) {
+	lex.ensure(0)
+	start := lex.pos - lex.base
+	if c := lex.buf[start]; c < utf8.RuneSelf {
+		return rune(c), 1
+	}
+	lex.ensure(utf8.UTFMax - 1)
+	return utf8.DecodeRune(lex.buf[start:])
+}
+
+// peek Returns the character n bytes past the current position, or 0 past the end of the source code.
+//
+// n - The distance from the current position.
 // Return type: byte
-func (lex *lexer) at() byte {
-	return lex.source[lex.pos]
+func (lex *Lexer) peek(n int) byte {
+	if !lex.ensure(n) {
+		return 0
+	}
+	return lex.buf[lex.pos-lex.base+n]
 }
 
-// remainder Returns the remaining part of the source code being lexed.
+// lookahead Returns up to n bytes of source code starting at the current position.
 //
-// No parameters.
+// n - The number of bytes wanted.
 // Return type: string
-func (lex *lexer) remainder() string {
-	return lex.source[lex.pos:]
+func (lex *Lexer) lookahead(n int) string {
+	lex.ensure(n - 1)
+	start := lex.pos - lex.base
+	end := start + n
+	if end > len(lex.buf) {
+		end = len(lex.buf)
+	}
+	return string(lex.buf[start:end])
 }
 
 // at_eof checks if the lexer has reached the end of the source code.
 //
 // It returns a boolean value indicating whether the lexer is at the end of the source code.
-func (lex *lexer) at_eof() bool {
-	return lex.pos >= len(lex.source)
+func (lex *Lexer) at_eof() bool {
+	return !lex.ensure(0)
 }
 
 // Tokenize Tokenizes the source code into a list of tokens.
+// It returns the first *LexError found, if any; use TokenizeWithErrors to collect every
+// problem instead. Like every function in this package, it never panics, whatever bytes
+// the source contains.
 //
 // source - The source code to be tokenized.
-// Return type: []Token
-func Tokenize(source string) []Token {
-	lex := createLexer(source)
-	// iterate while we still have tokens
-	for !lex.at_eof() {
-		matched := false
-		for _, pattern := range lex.patterns {
-			// match the pattern
-			loc := pattern.regex.FindStringIndex(lex.remainder())
-			if loc != nil && loc[0] == 0 {
-				pattern.handler(lex, pattern.regex)
-				matched = true
-				break
-			}
+// Return type: []Token, error
+func Tokenize(source string) ([]Token, error) {
+	return TokenizeFile("", source)
+}
 
+// TokenizeFile Tokenizes the source code of a named file into a list of tokens.
+// The file name is recorded in the span of every token. Like Tokenize, it returns
+// the first *LexError found, if any.
+//
+// filename - The name of the file the source code was read from.
+// source - The source code to be tokenized.
+// Return type: []Token, error
+func TokenizeFile(filename string, source string) ([]Token, error) {
+	tokens, errs := TokenizeFileWithErrors(filename, source)
+	if len(errs) > 0 {
+		return tokens, errs[0]
+	}
+	return tokens, nil
+}
+
+// TokenizeWithErrors Tokenizes the source code into a list of tokens without panicking.
+// Every unrecognized character is reported as a *LexError and emitted as an ILLEGAL
+// token, and lexing continues with the next character.
+//
+// source - The source code to be tokenized.
+// Return type: []Token, []error
+func TokenizeWithErrors(source string) ([]Token, []error) {
+	return TokenizeFileWithErrors("", source)
+}
+
+// TokenizeFileWithErrors Tokenizes the source code of a named file without panicking.
+// It behaves like TokenizeWithErrors and records the file name in every token span.
+//
+// filename - The name of the file the source code was read from.
+// source - The source code to be tokenized.
+// Return type: []Token, []error
+func TokenizeFileWithErrors(filename string, source string) ([]Token, []error) {
+	lex := createLexer([]byte(source))
+	lex.file = filename
+	tokens := make([]Token, 0, len(source)/8) // typical source has a token every few bytes
+	for {
+		token := lex.Next()
+		tokens = append(tokens, token)
+		if token.Kind == EOF {
+			return tokens, lex.Errors()
 		}
-		// you may improve this error message, like location of error
-		if !matched {
-			panic(fmt.Sprintf("Lexer:Error -> unrecognized token near %s\n", lex.remainder()))
-		}
+	}
+}
 
+// scanToken Scans the token, whitespace or comment at the current position.
+//
+// The current character decides which handler runs, so no handler is ever tried on
+// input it cannot match. Operators are matched longest first using the lexer's Config.
+// A newline, or a doc comment, after a token that can end a statement first inserts a semicolon.
+//
+// No return value.
+func (lex *Lexer) scanToken() {
+	c := lex.at()
+	switch {
+	case lex.pos == 0 && (c == byteOrderMark || lex.lookahead(2) == "#!"):
+		fileStartHandler(lex)
+	case lex.semiAllowed() && (isNewline(c) || lex.atDocComment()):
+		lex.insertSemicolon()
+	case isSpace(c):
+		skipHandler(lex)
+	case c == '/' && lex.peek(1) == '/':
+		commentHandler(lex)
+	case c == '/' && lex.peek(1) == '*':
+		blockCommentHandler(lex)
+	case c == '"':
+		stringHandler(lex)
+	case c == '`':
+		rawStringHandler(lex)
+	case c == '}' && lex.closesTemplateExpr():
+		templateHandler(lex)
+	case isDigit(c):
+		numberHandler(lex)
+	case isIdentStart(c):
+		symbolHandler(lex)
+	default:
+		operatorHandler(lex)
 	}
-	lex.push(NewToken(EOF, "eof"))
-	return lex.Tokens
 }
 
-// defaultHandler Returns a regexHandler function that handles tokens of a specific kind.
+// operatorHandler Handles an operator or punctuation token at the current position.
+// The longest operator spelling in the lexer's Config that matches wins.
+// Characters that do not start any operator are handed to illegalHandler.
 //
-// kind - The type of token to be handled.
-// value - The value of the token to be handled.
-// Return type: regexHandler
-func defaultHandler(kind TokenKind, value string) regexHandler {
-	return func(lex *lexer, regex *regexp.Regexp) {
-		// advance the lexer's position past the value we just reached
-		lex.advanceN(len(value))
-		lex.push(NewToken(kind, value))
+// lex - The lexer instance used to process the source code.
+// Return type: No return value.
+func operatorHandler(lex *Lexer) {
+	rest := lex.lookahead(lex.config.maxOperatorLength)
+	for n := len(rest); n > 0; n-- {
+		if kind, exists := lex.config.operators[rest[:n]]; exists {
+			start := lex.position()
+			lex.advanceN(n)
+			lex.push(NewTokenAt(kind, rest[:n], lex.spanFrom(start)))
+			lex.countBraces(kind)
+			return
+		}
 	}
+
+	illegalHandler(lex)
 }
 
 // createLexer Creates a new lexer instance from the given source code.
 //
-// source - The source code to be lexed.
-// Return type: *lexer
-func createLexer(source string) *lexer {
-	return &lexer{
-		pos:    0,
-		source: source,
-		Tokens: make([]Token, 0),
-		patterns: []regexPattern{
-			// define all of the regex patterns
-			{regexp.MustCompile(`\s+`), skipHandler},
-			{regexp.MustCompile(`\/\/.*`), commentHandler},
-			{regexp.MustCompile(`"[^"]*"`), stringHandler},
-			{regexp.MustCompile(`[0-9]+(\.[0-9]+)?`), numberHandler},
-			{regexp.MustCompile(`[a-zA-Z_][a-zA-Z0-9_]*`), symbolHandler},
-			{regexp.MustCompile(`\[`), defaultHandler(OPEN_BRACKET, "[")},
-			{regexp.MustCompile(`\]`), defaultHandler(CLOSE_BRACKET, "]")},
-			{regexp.MustCompile(`\{`), defaultHandler(OPEN_CURLY, "{")},
-			{regexp.MustCompile(`\}`), defaultHandler(CLOSE_CURLY, "}")},
-			{regexp.MustCompile(`\(`), defaultHandler(OPEN_PAREN, "(")},
-			{regexp.MustCompile(`\)`), defaultHandler(CLOSE_PAREN, ")")},
-			{regexp.MustCompile(`==`), defaultHandler(EQUALS, "==")},
-			{regexp.MustCompile(`!=`), defaultHandler(NOT_EQUALS, "!=")},
-			{regexp.MustCompile(`=`), defaultHandler(ASSIGNMENT, "=")},
-			{regexp.MustCompile(`!`), defaultHandler(NOT, "!")},
-			{regexp.MustCompile(`<=`), defaultHandler(LESS_EQUALS, "<=")},
-			{regexp.MustCompile(`<`), defaultHandler(LESS, "<")},
-			{regexp.MustCompile(`>=`), defaultHandler(GREATER_EQUALS, ">=")},
-			{regexp.MustCompile(`>`), defaultHandler(GREATER, ">")},
-			{regexp.MustCompile(`\|\|`), defaultHandler(OR, "||")},
-			{regexp.MustCompile(`&&`), defaultHandler(AND, "&&")},
-			{regexp.MustCompile(`\.\.`), defaultHandler(DOT_DOT, "..")},
-			{regexp.MustCompile(`\.`), defaultHandler(DOT, ".")},
-			{regexp.MustCompile(`;`), defaultHandler(SEMI_COLON, ";")},
-			{regexp.MustCompile(`:`), defaultHandler(COLON, ":")},
-			{regexp.MustCompile(`\?\?=`), defaultHandler(NULLISH_ASSIGNMENT, "??=")},
-			{regexp.MustCompile(`\?`), defaultHandler(QUESTION, "?")},
-			{regexp.MustCompile(`,`), defaultHandler(COMMA, ",")},
-			{regexp.MustCompile(`\+\+`), defaultHandler(PLUS_PLUS, "++")},
-			{regexp.MustCompile(`--`), defaultHandler(MINUS_MINUS, "--")},
-			{regexp.MustCompile(`\+=`), defaultHandler(PLUS_EQUALS, "+=")},
-			{regexp.MustCompile(`-=`), defaultHandler(MINUS_EQUALS, "-=")},
-			{regexp.MustCompile(`\+`), defaultHandler(PLUS, "+")},
-			{regexp.MustCompile(`-`), defaultHandler(DASH, "-")},
-			{regexp.MustCompile(`/`), defaultHandler(SLASH, "/")},
-			{regexp.MustCompile(`\*`), defaultHandler(STAR, "*")},
-			{regexp.MustCompile(`%`), defaultHandler(PERCENT, "%")},
-			// order of patterns matter, if = is before == then we will get two assignments
-			// operator instead of one equals
-		},
+// source - The source code to be lexed, or nil when it will be read from a reader.
+// Return type: *Lexer
+func createLexer(source []byte) *Lexer {
+	return &Lexer{
+		pos:      0,
+		line:     1,
+		column:   1,
+		column16: 1,
+		buf:      source,
+		queue:    make([]Token, 0, 2),
+		config:   defaultConfig,
+	}
+}
+
+// illegalHandler Handles a character that no pattern matched.
+// The character is reported as an error and emitted as an ILLEGAL token so lexing can continue.
+//
+// lex - The lexer instance used to process the source code.
+// Return type: No return value.
+func illegalHandler(lex *Lexer) {
+	r, size := lex.atRune()
+	start := lex.position()
+	lex.advanceN(size)
+	span := lex.spanFrom(start)
+	value := lex.textFrom(start.Offset)
+	if r == utf8.RuneError && size == 1 {
+		lex.error(span, value, "invalid UTF-8 encoding")
+	} else {
+		lex.error(span, value, "unrecognized character")
 	}
+	lex.push(NewTokenAt(ILLEGAL, value, span))
 }
 
 // numberHandler Handles a numeric token match in the source code.
 //
+// Integers may use the 0x, 0o and 0b prefixes, floats may have a fraction and an exponent, and '_'
+// may separate digits in either. Integers are emitted as INT tokens and everything else
+// as FLOAT tokens; in both cases the value has the separators removed. Malformed
+// literals are reported as errors and emitted as ILLEGAL tokens.
+//
 // lex - The lexer instance used to process the source code.
-// regex - The regular expression used to match the numeric token.
 // Return type: No return value.
-func numberHandler(lex *lexer, regex *regexp.Regexp) {
-	match := regex.FindString(lex.remainder())
-	lex.push(NewToken(NUMBER, match))
-	lex.advanceN(len(match))
+func numberHandler(lex *Lexer) {
+	start := lex.position()
+	kind, message := scanNumber(lex)
+	span := lex.spanFrom(start)
+	text := lex.textFrom(start.Offset)
+
+	if message != "" {
+		lex.error(span, text, message)
+		lex.push(NewTokenAt(ILLEGAL, text, span))
+		return
+	}
+
+	lex.push(NewTokenAt(kind, strings.ReplaceAll(text, "_", ""), span))
+}
+
+// numberBases Maps the letter of a base prefix to the name and digits of the base.
+var numberBases = map[byte]struct {
+	name   string
+	digits string
+}{
+	'x': {"hexadecimal", "0123456789abcdefABCDEF"},
+	'X': {"hexadecimal", "0123456789abcdefABCDEF"},
+	'o': {"octal", "01234567"},
+	'O': {"octal", "01234567"},
+	'b': {"binary", "01"},
+	'B': {"binary", "01"},
+}
+
+// scanNumber Advances the lexer past a numeric literal and validates it.
+//
+// lex - The lexer instance, positioned on the first digit.
+// Return type: TokenKind (INT or FLOAT), string (error message, empty if the literal is valid)
+func scanNumber(lex *Lexer) (TokenKind, string) {
+	start := lex.pos
+	rest := lex.lookahead(2)
+	if len(rest) > 1 && rest[0] == '0' {
+		if base, ok := numberBases[rest[1]]; ok {
+			lex.advanceN(2)
+			digits := lex.scanWhile(isIdentContinue)
+			if strings.Trim(digits, "_") == "" {
+				return INT, base.name + " literal has no digits"
+			}
+			for _, c := range digits {
+				if c != '_' && !strings.ContainsRune(base.digits, c) {
+					return INT, fmt.Sprintf("invalid digit %q in %s literal", c, base.name)
+				}
+			}
+			if !validSeparators("0" + digits) {
+				return INT, "'_' must separate successive digits"
+			}
+			return INT, ""
+		}
+	}
+
+	kind := INT
+	mantissa := lex.scanWhile(isDigitOrSeparator)
+
+	if lex.peek(0) == '.' && isDigit(rune(lex.peek(1))) {
+		kind = FLOAT
+		lex.advanceN(1)
+		mantissa += "." + lex.scanWhile(isDigitOrSeparator)
+	}
+
+	if !lex.at_eof() && (lex.at() == 'e' || lex.at() == 'E') {
+		kind = FLOAT
+		lex.advanceN(1)
+		if !lex.at_eof() && (lex.at() == '+' || lex.at() == '-') {
+			lex.advanceN(1)
+		}
+		exponent := lex.scanWhile(isDigitOrSeparator)
+		if strings.Trim(exponent, "_") == "" {
+			lex.scanWhile(isIdentContinue)
+			return kind, "exponent has no digits"
+		}
+		if !validSeparators(exponent) {
+			return kind, "'_' must separate successive digits"
+		}
+	}
+
+	if suffix := lex.scanWhile(isIdentContinue); suffix != "" {
+		first, _ := utf8.DecodeRuneInString(suffix)
+		return kind, fmt.Sprintf("invalid character %q in numeric literal", first)
+	}
+
+	for _, part := range strings.Split(mantissa, ".") {
+		if !validSeparators(part) {
+			return kind, "'_' must separate successive digits"
+		}
+	}
+
+	if kind == FLOAT {
+		_, err := strconv.ParseFloat(strings.ReplaceAll(lex.textFrom(start), "_", ""), 64)
+		if err != nil {
+			return kind, "floating-point literal out of range"
+		}
+	}
+
+	return kind, ""
+}
+
+// scanWhile Advances the lexer while the current character satisfies accept.
+//
+// accept - The predicate deciding whether a character belongs to the run.
+// Return type: string (the characters that were skipped)
+func (lex *Lexer) scanWhile(accept func(c rune) bool) string {
+	start := lex.pos
+	for !lex.at_eof() && accept(lex.at()) {
+		lex.advance()
+	}
+	return lex.textFrom(start)
+}
+
+// validSeparators Reports whether every '_' in digits sits between two digits.
+//
+// digits - The digits of a numeric literal, without base prefix or sign.
+// Return type: bool
+func validSeparators(digits string) bool {
+	if strings.HasPrefix(digits, "_") || strings.HasSuffix(digits, "_") {
+		return false
+	}
+	return !strings.Contains(digits, "__")
+}
 
+// isDigit Reports whether c is a decimal digit.
+//
+// c - The character to check.
+// Return type: bool
+func isDigit(c rune) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isDigitOrSeparator Reports whether c is a decimal digit or the '_' digit separator.
+//
+// c - The character to check.
+// Return type: bool
+func isDigitOrSeparator(c rune) bool {
+	return isDigit(c) || c == '_'
 }
 
-// skipHandler Skips a token match in the source code.
+// isIdentStart Reports whether c can start an identifier.
+// Identifiers follow Unicode XID_Start, approximated as letters, letter numbers and
+// Other_ID_Start, with '_' allowed as well.
+//
+// c - The character to check.
+// Return type: bool
+func isIdentStart(c rune) bool {
+	if c < utf8.RuneSelf {
+		return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+	}
+	return unicode.IsLetter(c) || unicode.In(c, unicode.Nl, unicode.Other_ID_Start)
+}
+
+// isIdentContinue Reports whether c can appear inside an identifier.
+// Identifiers follow Unicode XID_Continue, approximated as the XID_Start characters plus
+// combining marks, decimal digits, connector punctuation and Other_ID_Continue.
+//
+// c - The character to check.
+// Return type: bool
+func isIdentContinue(c rune) bool {
+	if c < utf8.RuneSelf {
+		return isIdentStart(c) || isDigit(c)
+	}
+	return isIdentStart(c) || unicode.In(c, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue)
+}
+
+// skipHandler Skips a run of whitespace in the source code.
+//
+// lex - The lexer instance used to process the source code.
+// Return type: No return value.
+func skipHandler(lex *Lexer) {
+	if !lex.keepTrivia {
+		if lex.semiAllowed() {
+			// stop at the newline, which inserts a semicolon
+			lex.scanWhile(func(c rune) bool { return isSpace(c) && !isNewline(c) })
+		} else {
+			lex.scanWhile(isSpace)
+		}
+		return
+	}
+
+	// When trivia is kept, every newline is recorded on its own so trailing trivia can stop at it.
+	start := lex.position()
+	switch {
+	case lex.lookahead(2) == "\r\n":
+		lex.advanceN(2)
+		lex.addTrivia(NEWLINE_TRIVIA, start)
+	case isNewline(lex.at()):
+		lex.advanceN(1)
+		lex.addTrivia(NEWLINE_TRIVIA, start)
+	default:
+		for !lex.at_eof() && isSpace(lex.at()) && !isNewline(lex.at()) {
+			lex.advanceN(1)
+		}
+		lex.addTrivia(WHITESPACE_TRIVIA, start)
+	}
+}
+
+// byteOrderMark Is the character some editors write at the start of UTF-8 files.
+const byteOrderMark = '\uFEFF'
+
+// fileStartHandler Skips a byte order mark and a #! shebang line at the very start of the source.
+// Both are kept as trivia. Columns on the first line are counted from after the byte order mark.
+//
+// lex - The lexer instance used to process the source code.
+// Return type: No return value.
+func fileStartHandler(lex *Lexer) {
+	if lex.at() == byteOrderMark {
+		start := lex.position()
+		lex.advance()
+		lex.column, lex.column16 = 1, 1
+		lex.addTrivia(WHITESPACE_TRIVIA, start)
+	}
+
+	if lex.lookahead(2) == "#!" {
+		start := lex.position()
+		lex.scanWhile(func(c rune) bool { return !isNewline(c) })
+		lex.addTrivia(LINE_COMMENT_TRIVIA, start)
+	}
+}
+
+// isSpace Reports whether c is a whitespace character.
+//
+// c - The character to check.
+// Return type: bool
+func isSpace(c rune) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// isNewline Reports whether c ends a line. A line ends with \n, \r\n or a lone \r;
+// the \n of a \r\n pair does not start another line.
+//
+// c - The character to check.
+// Return type: bool
+func isNewline(c rune) bool {
+	return c == '\n' || c == '\r'
+}
+
+// CountLines Returns the number of line endings in text, counting \r\n once.
+//
+// text - The text to count the line endings of.
+// Return type: int
+func CountLines(text string) int {
+	return strings.Count(text, "\n") + strings.Count(text, "\r") - strings.Count(text, "\r\n")
+}
+
+// stringHandler Handles a string literal in the source code.
+// Escape sequences are decoded as the body is scanned. The token's Value holds the decoded
+// string and its Text holds the literal exactly as written, quotes included.
+// A string containing ${ is a template string and is emitted as TEMPLATE_HEAD instead.
 //
 // lex - The lexer instance used to process the source code.
-// regex - The regular expression used to match the token.
 // Return type: No return value.
-func skipHandler(lex *lexer, regex *regexp.Regexp) {
-	match := regex.FindStringIndex(lex.remainder())
-	lex.advanceN(match[1])
+func stringHandler(lex *Lexer) {
+	start := lex.position()
+	lex.advanceN(1) // opening quote
+	value, interpolated := scanStringBody(lex, start)
+
+	if interpolated {
+		lex.push(NewTokenAt(TEMPLATE_HEAD, value, lex.spanFrom(start)))
+		lex.templates = append(lex.templates, 0)
+		return
+	}
+	lex.push(NewTokenAt(STRING, value, lex.spanFrom(start)))
+}
+
+// rawStringHandler Handles a raw string literal enclosed in backticks.
+//
+// A raw string may span lines and its backslashes are kept literally, so `C:\dir` and
+// `\d+` mean what they say. Line endings inside it are normalised to \n. A raw string opened
+// with three backticks is a heredoc-style block: a blank first and last line are dropped and the
+// indentation shared by all remaining lines is stripped, so the block can be indented with the
+// code around it. Both forms are emitted as STRING tokens flagged RAW, and the block form is
+// also flagged INDENTED.
+//
+// lex - The lexer instance used to process the source code.
+// Return type: No return value.
+func rawStringHandler(lex *Lexer) {
+	start := lex.position()
+	delimiter := "`"
+	if lex.lookahead(3) == "```" {
+		delimiter = "```"
+	}
+	lex.advanceN(len(delimiter))
+
+	body := lex.pos
+	for !lex.at_eof() && lex.lookahead(len(delimiter)) != delimiter {
+		lex.advance()
+	}
+	value := normaliseLineEndings(lex.textFrom(body))
+
+	if lex.at_eof() {
+		lex.error(lex.spanFrom(start), "", "unterminated raw string literal")
+	} else {
+		lex.advanceN(len(delimiter))
+	}
+
+	flags := RAW
+	if delimiter == "```" {
+		value = stripIndentation(value)
+		flags |= INDENTED
+	}
+	lex.push(NewTokenAt(STRING, value, lex.spanFrom(start)))
+	lex.queue[len(lex.queue)-1].Flags |= flags
+}
+
+// normaliseLineEndings Replaces every \r\n and lone \r in text with \n.
+//
+// text - The text to normalise.
+// Return type: string
+func normaliseLineEndings(text string) string {
+	if !strings.Contains(text, "\r") {
+		return text
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+}
+
+// stripIndentation Removes a blank first and last line from the body of a ``` block
+// and strips the leading whitespace that all of its non-blank lines share.
+//
+// text - The body of the block, with line endings normalised to \n.
+// Return type: string
+func stripIndentation(text string) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > 1 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > 1 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := ""
+	first := true
+	for _, line := range lines {
+		content := strings.TrimLeft(line, " \t")
+		if content == "" {
+			continue
+		}
+		lead := line[:len(line)-len(content)]
+		if first {
+			indent, first = lead, false
+			continue
+		}
+		for !strings.HasPrefix(lead, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+
+	for i, line := range lines {
+		if strings.HasPrefix(line, indent) {
+			lines[i] = line[len(indent):]
+		} else {
+			lines[i] = "" // a blank line shorter than the indentation
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// templateHandler Handles the } that closes a ${ expression inside a template string.
+//
+// "hello ${user.name}!" is lexed as TEMPLATE_HEAD ("hello "), the tokens of the expression,
+// and TEMPLATE_TAIL ("!"). When a template has several expressions, the literal text between
+// them is lexed as TEMPLATE_MIDDLE tokens. The Text of each template token includes the
+// delimiters around it, so the token stream still covers the source exactly.
+//
+// lex - The lexer instance, positioned on the closing }.
+// Return type: No return value.
+func templateHandler(lex *Lexer) {
+	start := lex.position()
+	lex.advanceN(1) // closing brace
+	value, interpolated := scanStringBody(lex, start)
+
+	if interpolated {
+		lex.push(NewTokenAt(TEMPLATE_MIDDLE, value, lex.spanFrom(start)))
+		return
+	}
+	lex.templates = lex.templates[:len(lex.templates)-1]
+	lex.push(NewTokenAt(TEMPLATE_TAIL, value, lex.spanFrom(start)))
+}
+
+// closesTemplateExpr Reports whether a } at the current position closes a ${ expression.
+//
+// Return type: bool
+func (lex *Lexer) closesTemplateExpr() bool {
+	return len(lex.templates) > 0 && lex.templates[len(lex.templates)-1] == 0
+}
+
+// countBraces Keeps track of the braces opened inside the innermost ${ expression,
+// so that only its matching } ends the expression.
+//
+// kind - The kind of the token that was just pushed.
+// No return value.
+func (lex *Lexer) countBraces(kind TokenKind) {
+	if len(lex.templates) == 0 {
+		return
+	}
+	switch kind {
+	case OPEN_CURLY:
+		lex.templates[len(lex.templates)-1]++
+	case CLOSE_CURLY:
+		lex.templates[len(lex.templates)-1]--
+	}
+}
+
+// scanStringBody Scans the characters of a string literal up to and including the closing
+// quote, or up to and including the ${ that starts an interpolated expression.
+// A $ that is not followed by { is an ordinary character, and \$ always stands for a $.
+//
+// lex - The lexer instance, positioned after the opening quote or closing brace.
+// start - The position where the token began, used for error reporting.
+// Return type: string (the decoded value), bool (whether the body stopped at ${)
+func scanStringBody(lex *Lexer, start Position) (string, bool) {
+	var value strings.Builder
+
+	for {
+		if lex.at_eof() || isNewline(lex.at()) {
+			lex.error(lex.spanFrom(start), "", "unterminated string literal")
+			return value.String(), false
+		}
+
+		c := lex.at()
+		if c == '"' {
+			lex.advanceN(1)
+			return value.String(), false
+		}
+
+		if c == '$' && lex.peek(1) == '{' {
+			lex.advanceN(2)
+			return value.String(), true
+		}
+
+		if c == '\\' {
+			escapeHandler(lex, &value)
+			continue
+		}
+
+		if r, size := lex.atRune(); r == utf8.RuneError && size == 1 {
+			invalid := lex.position()
+			lex.advanceN(1)
+			lex.error(lex.spanFrom(invalid), lex.textFrom(invalid.Offset), "invalid UTF-8 encoding")
+		} else {
+			lex.advanceN(size)
+		}
+		value.WriteRune(c)
+	}
+}
+
+// escapeHandler Decodes the escape sequence at the lexer's position into value.
+//
+// Supported escapes are \n, \t, \r, \0, \\, \", \$, \xHH (a code point up to U+00FF)
+// and \u{H...} (any code point, one to six hex digits). Unknown or malformed escapes
+// are reported as errors and copied into value unchanged.
+//
+// lex - The lexer instance, positioned on the backslash.
+// value - The builder receiving the decoded string.
+// Return type: No return value.
+func escapeHandler(lex *Lexer, value *strings.Builder) {
+	start := lex.position()
+	lex.advanceN(1) // backslash
+
+	if lex.at_eof() || isNewline(lex.at()) {
+		// reported as an unterminated string by the caller
+		value.WriteByte('\\')
+		return
+	}
 
+	c := lex.at()
+	if decoded, ok := simpleEscapes[c]; ok {
+		lex.advanceN(1)
+		value.WriteRune(decoded)
+		return
+	}
+
+	switch c {
+	case 'x':
+		lex.advanceN(1)
+		digits := lex.scanHexDigits(2)
+		if len(digits) != 2 {
+			lex.invalidEscape(start, value, "invalid hex escape, expected two hex digits")
+			return
+		}
+		code, _ := strconv.ParseUint(digits, 16, 32)
+		value.WriteRune(rune(code))
+
+	case 'u':
+		lex.advanceN(1)
+		if lex.at_eof() || lex.at() != '{' {
+			lex.invalidEscape(start, value, "invalid unicode escape, expected \\u{...}")
+			return
+		}
+		lex.advanceN(1)
+		digits := lex.scanHexDigits(6)
+		if len(digits) == 0 || lex.at_eof() || lex.at() != '}' {
+			lex.invalidEscape(start, value, "invalid unicode escape, expected one to six hex digits and a closing }")
+			return
+		}
+		lex.advanceN(1)
+		code, _ := strconv.ParseUint(digits, 16, 32)
+		if code > unicode.MaxRune || (code >= 0xD800 && code <= 0xDFFF) {
+			lex.invalidEscape(start, value, "invalid unicode escape, not a valid code point")
+			return
+		}
+		value.WriteRune(rune(code))
+
+	default:
+		lex.advance()
+		lex.invalidEscape(start, value, "unknown escape sequence")
+	}
+}
+
+// invalidEscape Reports a malformed escape sequence and keeps its text in the decoded value.
+//
+// start - The position of the backslash that began the escape.
+// value - The builder receiving the decoded string.
+// message - A short description of the problem.
+// No return value.
+func (lex *Lexer) invalidEscape(start Position, value *strings.Builder, message string) {
+	span := lex.spanFrom(start)
+	text := lex.textFrom(start.Offset)
+	lex.error(span, text, message)
+	value.WriteString(text)
+}
+
+// simpleEscapes Maps the character following a backslash to the character it stands for.
+var simpleEscapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+	'$':  '$',
+}
+
+// scanHexDigits Advances the lexer past at most max hex digits.
+//
+// max - The maximum number of digits to consume.
+// Return type: string (the digits that were consumed)
+func (lex *Lexer) scanHexDigits(max int) string {
+	start := lex.pos
+	for lex.pos-start < max && !lex.at_eof() && isHexDigit(lex.at()) {
+		lex.advanceN(1)
+	}
+	return lex.textFrom(start)
 }
 
-// stringHandler Handles a string token match in the source code.
+// isHexDigit Reports whether c is a hexadecimal digit.
+//
+// c - The character to check.
+// Return type: bool
+func isHexDigit(c rune) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// commentHandler Handles a line comment in the source code.
+// Ordinary comments are discarded unless trivia is being kept. Comments starting with
+// exactly three slashes are doc comments and are emitted as DOC_COMMENT tokens whose value is the comment text
+// without the slashes and the single space that usually follows them.
 //
 // lex - The lexer instance used to process the source code.
-// regex - The regular expression used to match the string token.
 // Return type: No return value.
-func stringHandler(lex *lexer, regex *regexp.Regexp) {
-	match := regex.FindStringIndex(lex.remainder())
-	stringLiteral := lex.remainder()[match[0]+1 : match[1]-1]
-	lex.push(NewToken(STRING, stringLiteral))
-	lex.advanceN(len(stringLiteral) + 2)
+func commentHandler(lex *Lexer) {
+	start := lex.position()
+	match := lex.scanWhile(func(c rune) bool { return !isNewline(c) })
+
+	if isDocComment(match) {
+		doc := strings.TrimPrefix(match[3:], " ")
+		lex.push(NewTokenAt(DOC_COMMENT, doc, lex.spanFrom(start)))
+		return
+	}
+	lex.addTrivia(LINE_COMMENT_TRIVIA, start)
+}
+
+// isDocComment Reports whether a line comment is a doc comment, starting with exactly three slashes.
+//
+// comment - The comment text, starting with its slashes.
+// Return type: bool
+func isDocComment(comment string) bool {
+	return strings.HasPrefix(comment, "///") && !strings.HasPrefix(comment, "////")
 }
 
-// commentHandler Handles a comment token match in the source code.
+// atDocComment Reports whether a doc comment starts at the current position.
+//
+// Return type: bool
+func (lex *Lexer) atDocComment() bool {
+	return isDocComment(lex.lookahead(4))
+}
+
+// blockCommentHandler Handles a block comment in the source code.
+// Block comments may be nested, so every /* inside the comment needs its own */.
+// Block comments are discarded unless trivia is being kept. A block comment spanning
+// lines can end a statement, just like a newline.
 //
 // lex - The lexer instance used to process the source code.
-// regex - The regular expression used to match the comment token.
 // Return type: No return value.
-func commentHandler(lex *lexer, regex *regexp.Regexp) {
-	match := regex.FindStringIndex(lex.remainder())
-	lex.advanceN(match[1])
+func blockCommentHandler(lex *Lexer) {
+	start := lex.position()
+	lex.advanceN(2)
+	depth := 1
+
+	for depth > 0 {
+		if lex.at_eof() {
+			lex.error(lex.spanFrom(start), "", "unterminated block comment")
+			break
+		}
 
+		switch rest := lex.lookahead(2); {
+		case rest == "/*":
+			depth++
+			lex.advanceN(2)
+		case rest == "*/":
+			depth--
+			lex.advanceN(2)
+		default:
+			lex.advance()
+		}
+	}
+	lex.addTrivia(BLOCK_COMMENT_TRIVIA, start)
+
+	// a comment spanning lines acts like a newline
+	if lex.semiAllowed() && strings.ContainsAny(lex.textFrom(start.Offset), "\r\n") {
+		lex.insertSemicolon()
+	}
 }
 
-// symbolHandler Handles a symbol token match in the source code.
+// symbolHandler Handles an identifier or keyword in the source code.
 //
 // lex - The lexer instance used to process the source code.
-// regex - The regular expression used to match the symbol token.
 // Return type: No return value.
-func symbolHandler(lex *lexer, regex *regexp.Regexp) {
-	value := regex.FindString(lex.remainder())
+func symbolHandler(lex *Lexer) {
+	start := lex.position()
+	value := lex.scanWhile(isIdentContinue)
 
-	if kind, exists := isReservedKeyword[value]; exists {
-		lex.push(NewToken(kind, value))
+	if kind, exists := lex.config.keywords[value]; exists {
+		lex.push(NewTokenAt(kind, value, lex.spanFrom(start)))
 	} else {
-		lex.push(NewToken(IDENTIFIER, value))
+		lex.push(NewTokenAt(IDENTIFIER, value, lex.spanFrom(start)))
 	}
-	lex.advanceN(len(value))
 
 }
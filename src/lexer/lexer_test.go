@@ -0,0 +1,118 @@
+package lexer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// chunkReader Is an io.Reader that returns at most size bytes per Read call, so the lexer
+// has to refill its buffer in the middle of tokens.
+type chunkReader struct {
+	source string
+	size   int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.source == "" {
+		return 0, io.EOF
+	}
+	n := copy(p[:min(len(p), r.size)], r.source)
+	r.source = r.source[n:]
+	return n, nil
+}
+
+// streamSource Returns source code several times larger than readChunkSize, with tokens,
+// multi-byte runes and a raw string longer than a whole chunk, so they straddle chunk boundaries.
+func streamSource(t *testing.T) string {
+	example, err := os.ReadFile("../../examples/01.lang")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var source strings.Builder
+	for source.Len() < 4*readChunkSize {
+		source.Write(example)
+		source.WriteString("\nlet größe = \"😀 ${a + 1} é\" // ünïcode\n")
+		source.WriteString("let raw = `" + strings.Repeat("x\r\n", readChunkSize/2) + "`\n")
+	}
+	source.WriteString("let last = 0x_FF")
+	return source.String()
+}
+
+// TestLexerStreams Checks that lexing from a reader that returns a few bytes at a time gives
+// exactly the tokens and errors of lexing the whole source at once, that Peek does not advance,
+// and that Next keeps returning EOF once the source is exhausted.
+func TestLexerStreams(t *testing.T) {
+	source := streamSource(t)
+	want, wantErrs := TokenizeWithErrors(source)
+
+	for _, size := range []int{1, 3, 7, readChunkSize + 1} {
+		lex := NewLexer("", &chunkReader{source: source, size: size})
+		for i, expected := range want {
+			if i%2 == 0 {
+				if peeked := lex.Peek(); !reflect.DeepEqual(peeked, expected) || !reflect.DeepEqual(lex.Peek(), expected) {
+					t.Fatalf("reading %d bytes at a time, Peek returned %v for token %d, want %v", size, peeked, i, expected)
+				}
+			}
+			if got := lex.Next(); !reflect.DeepEqual(got, expected) {
+				t.Fatalf("reading %d bytes at a time, token %d is %v, want %v", size, i, got, expected)
+			}
+		}
+
+		for i := 0; i < 3; i++ {
+			if token := lex.Next(); token.Kind != EOF {
+				t.Errorf("reading %d bytes at a time, Next returned %v after EOF", size, token)
+			}
+		}
+		if !reflect.DeepEqual(lex.Errors(), wantErrs) {
+			t.Errorf("reading %d bytes at a time reported %v, want %v", size, lex.Errors(), wantErrs)
+		}
+	}
+}
+
+// benchmarkSource Returns roughly size bytes of source code built by repeating examples/01.lang.
+func benchmarkSource(b *testing.B, size int) string {
+	example, err := os.ReadFile("../../examples/01.lang")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var source strings.Builder
+	for source.Len() < size {
+		source.Write(example)
+		source.WriteString("\n")
+	}
+	return source.String()
+}
+
+// BenchmarkTokenize Lexes sources of growing size. The lexer is linear when the
+// throughput (MB/s) stays the same as the input grows.
+func BenchmarkTokenize(b *testing.B) {
+	for _, size := range []int{64 << 10, 1 << 20, 4 << 20, 16 << 20} {
+		source := benchmarkSource(b, size)
+		b.Run(fmt.Sprintf("%dKB", size>>10), func(b *testing.B) {
+			b.SetBytes(int64(len(source)))
+			for i := 0; i < b.N; i++ {
+				TokenizeWithErrors(source)
+			}
+		})
+	}
+}
+
+// BenchmarkTokenizeWhitespace Lexes a source made of one long run of whitespace
+// and comments, the worst case for the old regex based lexer.
+func BenchmarkTokenizeWhitespace(b *testing.B) {
+	for _, size := range []int{64 << 10, 1 << 20, 4 << 20} {
+		source := strings.Repeat("   // comment\n", size/14) + "x"
+		b.Run(fmt.Sprintf("%dKB", size>>10), func(b *testing.B) {
+			b.SetBytes(int64(len(source)))
+			for i := 0; i < b.N; i++ {
+				TokenizeWithErrors(source)
+			}
+		})
+	}
+}
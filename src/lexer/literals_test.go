@@ -0,0 +1,222 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+// literalTest Describes the first token a source should lex to and the error it should report, if any.
+type literalTest struct {
+	source string
+	kind   TokenKind
+	value  string
+	err    string
+}
+
+// checkLiterals Lexes every test source and compares its first token and errors with the test.
+func checkLiterals(t *testing.T, tests []literalTest) {
+	t.Helper()
+	for _, test := range tests {
+		tokens, errs := TokenizeWithErrors(test.source)
+		if len(tokens) == 0 {
+			t.Errorf("%q produced no tokens", test.source)
+			continue
+		}
+		if tokens[0].Kind != test.kind || tokens[0].Value != test.value {
+			t.Errorf("%q lexed as %s %q, want %s %q", test.source, tokens[0].Kind, tokens[0].Value, test.kind, test.value)
+		}
+
+		got := ""
+		if len(errs) > 0 {
+			got = errs[0].(*LexError).Message
+		}
+		if got != test.err || len(errs) > 1 {
+			t.Errorf("%q reported %v, want %q", test.source, errs, test.err)
+		}
+	}
+}
+
+// TestStringEscapes Checks the decoded value of every escape sequence and the errors for malformed ones.
+func TestStringEscapes(t *testing.T) {
+	checkLiterals(t, []literalTest{
+		{`"plain"`, STRING, "plain", ""},
+		{`"a\nb\tc\rd"`, STRING, "a\nb\tc\rd", ""},
+		{`"\0\\\"\$"`, STRING, "\x00\\\"$", ""},
+		{`"\x41\x7e\xff"`, STRING, "A~ÿ", ""},
+		{`"\u{41}\u{e9}\u{1F600}"`, STRING, "Aé😀", ""},
+		{`"\u{10FFFF}"`, STRING, "\U0010FFFF", ""},
+		{`"$a {b}"`, STRING, "$a {b}", ""},
+		{`"é😀"`, STRING, "é😀", ""},
+
+		{`"\q"`, STRING, `\q`, "unknown escape sequence"},
+		{`"\é"`, STRING, `\é`, "unknown escape sequence"},
+		{`"\x4"`, STRING, `\x4`, "invalid hex escape, expected two hex digits"},
+		{`"\xg0"`, STRING, `\xg0`, "invalid hex escape, expected two hex digits"},
+		{`"\u0041"`, STRING, `\u0041`, "invalid unicode escape, expected \\u{...}"},
+		{`"\u{}"`, STRING, `\u{}`, "invalid unicode escape, expected one to six hex digits and a closing }"},
+		{`"\u{1234567}"`, STRING, `\u{1234567}`, "invalid unicode escape, expected one to six hex digits and a closing }"},
+		{`"\u{110000}"`, STRING, `\u{110000}`, "invalid unicode escape, not a valid code point"},
+		{`"\u{D800}"`, STRING, `\u{D800}`, "invalid unicode escape, not a valid code point"},
+
+		{`"abc`, STRING, "abc", "unterminated string literal"},
+		{"\"abc\nlet", STRING, "abc", "unterminated string literal"},
+		{`"abc\`, STRING, `abc\`, "unterminated string literal"},
+	})
+}
+
+// TestStringText Checks that string tokens keep their source text, escapes and all, next to the decoded value.
+func TestStringText(t *testing.T) {
+	tokens, _ := TokenizeWithErrors(`"a\u{1F600}\n"`)
+	if tokens[0].Text != `"a\u{1F600}\n"` || tokens[0].Value != "a😀\n" {
+		t.Errorf("string lexed with text %q and value %q", tokens[0].Text, tokens[0].Value)
+	}
+}
+
+// TestNumberLiterals Checks the kind and value of numeric literals and the errors for malformed ones.
+func TestNumberLiterals(t *testing.T) {
+	checkLiterals(t, []literalTest{
+		{"0", INT, "0", ""},
+		{"1_000_000", INT, "1000000", ""},
+		{"0x_FF", INT, "0xFF", ""},
+		{"0XdeadBEEF", INT, "0XdeadBEEF", ""},
+		{"0o17", INT, "0o17", ""},
+		{"0b1010_0101", INT, "0b10100101", ""},
+		{"123456789012345678901234567890", INT, "123456789012345678901234567890", ""},
+		{"1.5", FLOAT, "1.5", ""},
+		{"1_0.2_5", FLOAT, "10.25", ""},
+		{"1e10", FLOAT, "1e10", ""},
+		{"2.5E-3", FLOAT, "2.5E-3", ""},
+		{"6e+2_3", FLOAT, "6e+23", ""},
+		{"1.e", INT, "1", ""},
+
+		{"0x", ILLEGAL, "0x", "hexadecimal literal has no digits"},
+		{"0b_", ILLEGAL, "0b_", "binary literal has no digits"},
+		{"0b102", ILLEGAL, "0b102", "invalid digit '2' in binary literal"},
+		{"0o8", ILLEGAL, "0o8", "invalid digit '8' in octal literal"},
+		{"0xfg", ILLEGAL, "0xfg", "invalid digit 'g' in hexadecimal literal"},
+		{"0x__1", ILLEGAL, "0x__1", "'_' must separate successive digits"},
+		{"1__0", ILLEGAL, "1__0", "'_' must separate successive digits"},
+		{"1_", ILLEGAL, "1_", "'_' must separate successive digits"},
+		{"1._5", INT, "1", ""},
+		{"1.5_", ILLEGAL, "1.5_", "'_' must separate successive digits"},
+		{"1e", ILLEGAL, "1e", "exponent has no digits"},
+		{"1e+", ILLEGAL, "1e+", "exponent has no digits"},
+		{"1e_5", ILLEGAL, "1e_5", "'_' must separate successive digits"},
+		{"12abc", ILLEGAL, "12abc", "invalid character 'a' in numeric literal"},
+		{"1e999", ILLEGAL, "1e999", "floating-point literal out of range"},
+	})
+}
+
+// TestRawStrings Checks the value and flags of raw strings and ``` blocks, including line ending normalisation.
+func TestRawStrings(t *testing.T) {
+	tests := []struct {
+		source string
+		value  string
+		flags  TokenFlags
+		err    string
+	}{
+		{"`C:\\dir\\n`", "C:\\dir\\n", RAW, ""},
+		{"`a ${b} \"c\"`", "a ${b} \"c\"", RAW, ""},
+		{"`a\r\nb\rc`", "a\nb\nc", RAW, ""},
+		{"``", "", RAW, ""},
+		{"```\n  a\n    b\n  ```", "a\n  b", RAW | INDENTED, ""},
+		{"```\r\n  a\r\n    b\r\n  ```", "a\n  b", RAW | INDENTED, ""},
+		{"```\r  a\r  b\r```", "a\nb", RAW | INDENTED, ""},
+		{"```a `b` c```", "a `b` c", RAW | INDENTED, ""},
+		{"`abc", "abc", RAW, "unterminated raw string literal"},
+		{"```\n  abc\n", "abc", RAW | INDENTED, "unterminated raw string literal"},
+	}
+
+	for _, test := range tests {
+		tokens, errs := TokenizeWithErrors(test.source)
+		token := tokens[0]
+		if token.Kind != STRING || token.Value != test.value || token.Flags != test.flags {
+			t.Errorf("%q lexed as %s %q with flags %d, want STRING %q with flags %d", test.source, token.Kind, token.Value, token.Flags, test.value, test.flags)
+		}
+
+		got := ""
+		if len(errs) > 0 {
+			got = errs[0].(*LexError).Message
+		}
+		if got != test.err || len(errs) > 1 {
+			t.Errorf("%q reported %v, want %q", test.source, errs, test.err)
+		}
+	}
+}
+
+// TestStripIndentation Checks how ``` blocks lose their blank first and last lines and their shared indentation.
+func TestStripIndentation(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"abc", "abc"},
+		{"\n  a\n  b\n", "a\nb"},
+		{"  \n  a\n    b\n  ", "a\n  b"},
+		{"\n\n  a\n\n", "\na\n"},
+		{"  a\n\n  b", "a\n\nb"},
+		{"    a\n \n    b", "a\n\nb"},
+		{"\ta\n\t\tb", "a\n\tb"},
+		{"\t  a\n\t b", " a\nb"},
+		{"  a\n\tb", "  a\n\tb"},
+		{"\t a\n \tb", "\t a\n \tb"},
+		{"a\n  b", "a\n  b"},
+		{"\n", ""},
+		{"  ", "  "},
+	}
+
+	for _, test := range tests {
+		if got := stripIndentation(test.text); got != test.want {
+			t.Errorf("stripIndentation(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+// TestTemplateStrings Checks how templates are split into head, middle and tail tokens around
+// their expressions, including braces and templates nested inside an expression.
+func TestTemplateStrings(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []string
+		err    string
+	}{
+		{`"hello ${user.name}!"`, []string{"template_head hello ", "user", ".", "name", "template_tail !"}, ""},
+		{`"a ${x} ${y} c"`, []string{"template_head a ", "x", "template_middle  ", "y", "template_tail  c"}, ""},
+		{`"${}"`, []string{"template_head ", "template_tail "}, ""},
+		{`"a ${ {x} } b"`, []string{"template_head a ", "{", "x", "}", "template_tail  b"}, ""},
+		{`"${ {a: {b}} }"`, []string{"template_head ", "{", "a", ":", "{", "b", "}", "}", "template_tail "}, ""},
+		{`"${ "in ${x}" }"`, []string{"template_head ", "template_head in ", "x", "template_tail ", "template_tail "}, ""},
+		{`"${x}\n\$${y}"`, []string{"template_head ", "x", "template_middle \n$", "y", "template_tail "}, ""},
+		{`"\${x}"`, []string{"string ${x}"}, ""},
+		{`"$x {y}"`, []string{"string $x {y}"}, ""},
+		{"\"a ${\nx}\"", []string{"template_head a ", "x", "template_tail "}, ""},
+		{`"a ${x`, []string{"template_head a ", "x"}, "unterminated template string, expected } to close ${"},
+		{`"a ${x} b`, []string{"template_head a ", "x", "template_tail  b"}, "unterminated string literal"},
+	}
+
+	for _, test := range tests {
+		tokens, errs := TokenizeWithErrors(test.source)
+		got := []string{}
+		for _, token := range tokens {
+			switch token.Kind {
+			case EOF, SEMI_COLON:
+			case STRING, TEMPLATE_HEAD, TEMPLATE_MIDDLE, TEMPLATE_TAIL:
+				got = append(got, token.Kind.String()+" "+token.Value)
+			default:
+				got = append(got, token.Text)
+			}
+		}
+		if strings.Join(got, "|") != strings.Join(test.want, "|") {
+			t.Errorf("%q lexed as %q, want %q", test.source, got, test.want)
+		}
+
+		message := ""
+		if len(errs) > 0 {
+			message = errs[0].(*LexError).Message
+		}
+		if message != test.err || len(errs) > 1 {
+			t.Errorf("%q reported %v, want %q", test.source, errs, test.err)
+		}
+	}
+}
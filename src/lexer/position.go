@@ -0,0 +1,67 @@
+package lexer
+
+import "fmt"
+
+/*
+This class definition defines a struct called `Position` in Go, which describes a single location in the source code. Here's a succinct explanation of what each field does:
+
+* `Offset int`: the byte offset from the start of the source, starting at 0.
+* `Line int`: the line number, starting at 1.
+* `Column int`: the column number in runes, starting at 1.
+* `UTF16Column int`: the column number in UTF-16 code units, starting at 1, as used by editor protocols such as LSP.
+
+A zero `Position` (line 0) is used for tokens that were not produced by the lexer, for example ones built with `NewToken`.
+*/
+type Position struct {
+	Offset      int
+	Line        int
+	Column      int
+	UTF16Column int
+}
+
+// IsValid Reports whether the position was produced by the lexer.
+//
+// Return type: bool
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+// String Returns the position formatted as line:column.
+//
+// Return type: string
+func (pos Position) String() string {
+	if !pos.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+/*
+This class definition defines a struct called `Span` in Go, which describes the region of source code a token covers. Here's a succinct explanation of what each field does:
+
+* `File string`: the name of the file the token came from, empty when the source was not read from a file.
+* `Start Position`: the position of the first byte of the token.
+* `End Position`: the position just past the last byte of the token.
+*/
+type Span struct {
+	File  string
+	Start Position
+	End   Position
+}
+
+// Len Returns the number of bytes covered by the span.
+//
+// Return type: int
+func (span Span) Len() int {
+	return span.End.Offset - span.Start.Offset
+}
+
+// String Returns the span formatted as file:line:column.
+//
+// Return type: string
+func (span Span) String() string {
+	if span.File == "" {
+		return span.Start.String()
+	}
+	return fmt.Sprintf("%s:%s", span.File, span.Start)
+}
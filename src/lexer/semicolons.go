@@ -0,0 +1,36 @@
+package lexer
+
+// semiAllowed Reports whether a newline at the current position ends a statement.
+//
+// As in Go, a semicolon is inserted whenever the last token can end a statement, whatever
+// brackets are open, so a missing ) cannot turn insertion off for the rest of the file. The
+// parser skips implicit semicolons inside argument lists, array literals and other brackets,
+// so those can still span several lines. The one exception is a template expression: its
+// tokens sit inside a string literal, which never ends a statement.
+//
+// Return type: bool
+func (lex *Lexer) semiAllowed() bool {
+	return lex.insertSemi && len(lex.templates) == 0
+}
+
+// insertSemicolon Emits an empty SEMI_COLON token flagged IMPLICIT at the current position.
+//
+// No return value.
+func (lex *Lexer) insertSemicolon() {
+	lex.push(NewTokenAt(SEMI_COLON, ";", lex.spanFrom(lex.position())))
+	lex.queue[len(lex.queue)-1].Flags |= IMPLICIT
+}
+
+// endsStatement Reports whether a token of the given kind can be the last token of a statement.
+// The rules follow Go: identifiers, literals, closing brackets, ++ and --.
+//
+// kind - The kind of the token.
+// Return type: bool
+func endsStatement(kind TokenKind) bool {
+	switch kind {
+	case IDENTIFIER, INT, FLOAT, STRING, TEMPLATE_TAIL, NULL, TRUE, FALSE,
+		CLOSE_PAREN, CLOSE_BRACKET, CLOSE_CURLY, PLUS_PLUS, MINUS_MINUS:
+		return true
+	}
+	return false
+}
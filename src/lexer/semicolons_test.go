@@ -0,0 +1,121 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+// semicolonString Lexes source and returns the text of its tokens separated by spaces, with
+// implicit semicolons written as ⏎, so tests can see exactly where semicolons were inserted.
+func semicolonString(t *testing.T, source string) string {
+	t.Helper()
+	tokens, errs := TokenizeWithErrors(source)
+	if len(errs) > 0 {
+		t.Errorf("lexing %q failed: %v", source, errs)
+	}
+
+	parts := []string{}
+	for _, token := range tokens {
+		switch {
+		case token.Kind == EOF:
+		case token.Is(IMPLICIT):
+			parts = append(parts, "⏎")
+		default:
+			parts = append(parts, token.Text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// TestSemicolonInsertion Checks where semicolons are inserted at line ends and at the end of the file.
+func TestSemicolonInsertion(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"a\nb", "a ⏎ b ⏎"},
+		{"1\n\"s\"\n2.5\n", "1 ⏎ \"s\" ⏎ 2.5 ⏎"},
+		{"f()\ng", "f ( ) ⏎ g ⏎"},
+		{"a[0]\ng", "a [ 0 ] ⏎ g ⏎"},
+		{"{}\ng", "{ } ⏎ g ⏎"},
+		{"a++\nb--\n", "a ++ ⏎ b -- ⏎"},
+		{"a = b +\nc", "a = b + c ⏎"},
+		{"f(a,\nb)", "f ( a , b ) ⏎"},
+		{"a.\nb", "a . b ⏎"},
+		{"let x =\n1", "let x = 1 ⏎"},
+		{"a\n\n\nb", "a ⏎ b ⏎"},
+		{"a", "a ⏎"},
+		{"a;", "a ;"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := semicolonString(t, test.source); got != test.want {
+			t.Errorf("%q lexed as %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+// TestSemicolonInsertionInBrackets Checks that, as in Go, open brackets do not stop insertion,
+// so a missing ) only affects the line it is on, while template expressions never get semicolons.
+func TestSemicolonInsertionInBrackets(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"f(\n  a,\n  b\n)", "f ( a , b ⏎ ) ⏎"},
+		{"[\n  1\n]", "[ 1 ⏎ ] ⏎"},
+		{"{\n  a\n}", "{ a ⏎ } ⏎"},
+		{"f(1\nlet b = 2\n", "f ( 1 ⏎ let b = 2 ⏎"},
+		{"\"${a\n}\"\nb", "\"${ a }\" ⏎ b ⏎"},
+	}
+
+	for _, test := range tests {
+		if got := semicolonString(t, test.source); got != test.want {
+			t.Errorf("%q lexed as %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+// TestSemicolonLineEndings Checks that CRLF and lone CR line endings insert semicolons like LF does.
+func TestSemicolonLineEndings(t *testing.T) {
+	for _, source := range []string{"a\r\nb\r\n", "a\rb\r", "a\r\n\r\nb", "a\n\rb"} {
+		if got := semicolonString(t, source); got != "a ⏎ b ⏎" {
+			t.Errorf("%q lexed as %q, want %q", source, got, "a ⏎ b ⏎")
+		}
+	}
+}
+
+// TestSemicolonComments Checks that a comment counts as a line end only when it contains or is followed by one.
+func TestSemicolonComments(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"a // note\nb", "a ⏎ b ⏎"},
+		{"a /* note */ + b", "a + b ⏎"},
+		{"a /* one\ntwo */ b", "a ⏎ b ⏎"},
+		{"a /* one\r\ntwo */ b", "a ⏎ b ⏎"},
+		{"a /* note */\nb", "a ⏎ b ⏎"},
+		{"a +/* one\ntwo */b", "a + b ⏎"},
+	}
+
+	for _, test := range tests {
+		if got := semicolonString(t, test.source); got != test.want {
+			t.Errorf("%q lexed as %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+// TestExplicitSemicolons Checks that code ending every statement with ; gets no implicit
+// semicolons, so it lexes the same whether or not its line ends are kept.
+func TestExplicitSemicolons(t *testing.T) {
+	source := "let a = f(1, 2);\nconst b: number = a[0] * 2;\nb++;\nif (a) { a--; };\n"
+	want := "let a = f ( 1 , 2 ) ; const b : number = a [ 0 ] * 2 ; b ++ ; if ( a ) { a -- ; } ;"
+
+	for _, text := range []string{source, strings.ReplaceAll(source, "\n", " "), strings.ReplaceAll(source, "\n", "\r\n")} {
+		if got := semicolonString(t, text); got != want {
+			t.Errorf("%q lexed as %q, want %q", text, got, want)
+		}
+	}
+}
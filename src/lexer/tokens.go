@@ -6,12 +6,18 @@ type TokenKind int
 
 const (
 	EOF TokenKind = iota
+	ILLEGAL
 	NULL
 	TRUE
 	FALSE
-	NUMBER
+	INT   // 42, 0xff, 1_000
+	FLOAT // 3.14, 1e-9
 	STRING
+	TEMPLATE_HEAD   // "text ${
+	TEMPLATE_MIDDLE // } text ${
+	TEMPLATE_TAIL   // } text"
 	IDENTIFIER
+	DOC_COMMENT // /// documentation
 
 	// Grouping & Braces
 	OPEN_BRACKET
@@ -40,6 +46,7 @@ const (
 	// Symbols
 	DOT
 	DOT_DOT
+	ELLIPSIS
 	SEMI_COLON
 	COLON
 	QUESTION
@@ -80,37 +87,272 @@ const (
 	NUM_TOKENS
 )
 
-var isReservedKeyword = map[string]TokenKind{
-	"let":     LET,
-	"const":   CONST,
-	"fn":      FN,
-	"if":      IF,
-	"else":    ELSE,
-	"for":     FOR,
-	"while":   WHILE,
-	"new":     NEW,
-	"import":  IMPORT,
-	"from":    FROM,
-	"class":   CLASS,
-	"true":    TRUE,
-	"false":   FALSE,
-	"foreach": FOREACH,
-	"export":  EXPORT,
-	"typeof":  TYPEOF,
-	"in":      IN,
+/*
+The `TokenCategory` type groups token kinds by the role they play in the language:
+
+  - `SPECIAL_TOKEN`: tokens with no spelling of their own, such as eof, illegal and doc comments.
+  - `LITERAL_TOKEN`: literal values and identifiers, including the `true`, `false` and `null` keywords.
+  - `KEYWORD_TOKEN`: reserved words such as `let` and `fn`.
+  - `CONTEXTUAL_KEYWORD_TOKEN`: words such as `from` and `in` that are keywords only in some grammar positions.
+    The lexer emits them as identifiers, so they stay usable as names, and the parser reinterprets them where needed.
+  - `OPERATOR_TOKEN`: operators such as `+`, `==` and `??=`.
+  - `PUNCTUATION_TOKEN`: brackets, separators and other punctuation such as `(`, `;` and `.`.
+
+The zero value, `NO_CATEGORY`, is used for kinds that are not in the registry.
+*/
+type TokenCategory int
+
+const (
+	NO_CATEGORY TokenCategory = iota
+	SPECIAL_TOKEN
+	LITERAL_TOKEN
+	KEYWORD_TOKEN
+	CONTEXTUAL_KEYWORD_TOKEN
+	OPERATOR_TOKEN
+	PUNCTUATION_TOKEN
+)
+
+/*
+The `tokenInfo` struct describes one token kind in the registry. It has three fields:
+
+- `name`: the name of the kind, as returned by `String` and accepted by `ParseTokenKind`.
+- `spelling`: the fixed source text of keywords, operators and punctuation, empty for tokens whose text varies.
+- `category`: the role the kind plays in the language.
+*/
+type tokenInfo struct {
+	name     string
+	spelling string
+	category TokenCategory
+}
+
+// tokenRegistry Describes every token kind. It is the single source of truth for token names,
+// keyword and operator spellings and categories; everything else is derived from it.
+var tokenRegistry = [NUM_TOKENS]tokenInfo{
+	EOF:             {"eof", "", SPECIAL_TOKEN},
+	ILLEGAL:         {"illegal", "", SPECIAL_TOKEN},
+	NULL:            {"null", "null", LITERAL_TOKEN},
+	TRUE:            {"true", "true", LITERAL_TOKEN},
+	FALSE:           {"false", "false", LITERAL_TOKEN},
+	INT:             {"int", "", LITERAL_TOKEN},
+	FLOAT:           {"float", "", LITERAL_TOKEN},
+	STRING:          {"string", "", LITERAL_TOKEN},
+	TEMPLATE_HEAD:   {"template_head", "", LITERAL_TOKEN},
+	TEMPLATE_MIDDLE: {"template_middle", "", LITERAL_TOKEN},
+	TEMPLATE_TAIL:   {"template_tail", "", LITERAL_TOKEN},
+	IDENTIFIER:      {"identifier", "", LITERAL_TOKEN},
+	DOC_COMMENT:     {"doc_comment", "", SPECIAL_TOKEN},
+
+	OPEN_BRACKET:  {"open_bracket", "[", PUNCTUATION_TOKEN},
+	CLOSE_BRACKET: {"close_bracket", "]", PUNCTUATION_TOKEN},
+	OPEN_CURLY:    {"open_curly", "{", PUNCTUATION_TOKEN},
+	CLOSE_CURLY:   {"close_curly", "}", PUNCTUATION_TOKEN},
+	OPEN_PAREN:    {"open_paren", "(", PUNCTUATION_TOKEN},
+	CLOSE_PAREN:   {"close_paren", ")", PUNCTUATION_TOKEN},
+
+	ASSIGNMENT: {"assignment", "=", OPERATOR_TOKEN},
+	EQUALS:     {"equals", "==", OPERATOR_TOKEN},
+	NOT_EQUALS: {"not_equals", "!=", OPERATOR_TOKEN},
+	NOT:        {"not", "!", OPERATOR_TOKEN},
+
+	LESS:           {"less", "<", OPERATOR_TOKEN},
+	LESS_EQUALS:    {"less_equals", "<=", OPERATOR_TOKEN},
+	GREATER:        {"greater", ">", OPERATOR_TOKEN},
+	GREATER_EQUALS: {"greater_equals", ">=", OPERATOR_TOKEN},
+
+	OR:  {"or", "||", OPERATOR_TOKEN},
+	AND: {"and", "&&", OPERATOR_TOKEN},
+
+	DOT:        {"dot", ".", PUNCTUATION_TOKEN},
+	DOT_DOT:    {"dot_dot", "..", OPERATOR_TOKEN},
+	ELLIPSIS:   {"ellipsis", "...", PUNCTUATION_TOKEN},
+	SEMI_COLON: {"semi_colon", ";", PUNCTUATION_TOKEN},
+	COLON:      {"colon", ":", PUNCTUATION_TOKEN},
+	QUESTION:   {"question", "?", PUNCTUATION_TOKEN},
+	COMMA:      {"comma", ",", PUNCTUATION_TOKEN},
+
+	PLUS_PLUS:          {"plus_plus", "++", OPERATOR_TOKEN},
+	MINUS_MINUS:        {"minus_minus", "--", OPERATOR_TOKEN},
+	PLUS_EQUALS:        {"plus_equals", "+=", OPERATOR_TOKEN},
+	MINUS_EQUALS:       {"minus_equals", "-=", OPERATOR_TOKEN},
+	NULLISH_ASSIGNMENT: {"nullish_assignment", "??=", OPERATOR_TOKEN},
+
+	PLUS:    {"plus", "+", OPERATOR_TOKEN},
+	DASH:    {"dash", "-", OPERATOR_TOKEN},
+	SLASH:   {"slash", "/", OPERATOR_TOKEN},
+	STAR:    {"star", "*", OPERATOR_TOKEN},
+	PERCENT: {"percent", "%", OPERATOR_TOKEN},
+
+	LET:     {"let", "let", KEYWORD_TOKEN},
+	CONST:   {"const", "const", KEYWORD_TOKEN},
+	CLASS:   {"class", "class", KEYWORD_TOKEN},
+	NEW:     {"new", "new", KEYWORD_TOKEN},
+	IMPORT:  {"import", "import", KEYWORD_TOKEN},
+	FROM:    {"from", "from", CONTEXTUAL_KEYWORD_TOKEN},
+	FN:      {"fn", "fn", KEYWORD_TOKEN},
+	IF:      {"if", "if", KEYWORD_TOKEN},
+	ELSE:    {"else", "else", KEYWORD_TOKEN},
+	FOREACH: {"foreach", "foreach", KEYWORD_TOKEN},
+	WHILE:   {"while", "while", KEYWORD_TOKEN},
+	FOR:     {"for", "for", KEYWORD_TOKEN},
+	EXPORT:  {"export", "export", KEYWORD_TOKEN},
+	TYPEOF:  {"typeof", "typeof", CONTEXTUAL_KEYWORD_TOKEN},
+	IN:      {"in", "in", CONTEXTUAL_KEYWORD_TOKEN},
+}
+
+// isReservedKeyword Maps the spelling of every reserved keyword, including true, false and null, to its kind.
+// Contextual keywords are left out so they lex as identifiers.
+// It is derived from tokenRegistry and is the keyword table of DefaultConfig.
+var isReservedKeyword = registrySpellings(func(info tokenInfo) bool {
+	return info.category == KEYWORD_TOKEN || (info.category == LITERAL_TOKEN && info.spelling != "")
+})
+
+// operators Maps the spelling of every operator and punctuation token to its kind.
+// It is derived from tokenRegistry and is the operator table of DefaultConfig.
+var operators = registrySpellings(func(info tokenInfo) bool {
+	return info.category == OPERATOR_TOKEN || info.category == PUNCTUATION_TOKEN
+})
+
+// registrySpellings Builds a map from spelling to kind for the registry entries selected by include.
+//
+// include - Reports whether an entry belongs in the map.
+// Return type: map[string]TokenKind
+func registrySpellings(include func(tokenInfo) bool) map[string]TokenKind {
+	spellings := map[string]TokenKind{}
+	for kind, info := range tokenRegistry {
+		if info.spelling != "" && include(info) {
+			spellings[info.spelling] = TokenKind(kind)
+		}
+	}
+	return spellings
+}
+
+// String Returns the name of the token kind, such as "let" or "open_paren".
+//
+// Return type: string
+func (kind TokenKind) String() string {
+	return TokenKindString(kind)
+}
+
+// Category Returns the category of the token kind, or NO_CATEGORY for kinds outside the registry.
+//
+// Return type: TokenCategory
+func (kind TokenKind) Category() TokenCategory {
+	if kind < 0 || kind >= NUM_TOKENS {
+		return NO_CATEGORY
+	}
+	return tokenRegistry[kind].category
+}
+
+// Spelling Returns the fixed source text of a keyword, operator or punctuation kind,
+// or an empty string for kinds whose text varies, such as identifiers.
+//
+// Return type: string
+func (kind TokenKind) Spelling() string {
+	if kind < 0 || kind >= NUM_TOKENS {
+		return ""
+	}
+	return tokenRegistry[kind].spelling
+}
+
+// MarshalText Encodes the token kind as its name, so kinds read well in JSON and other text formats.
+//
+// Return type: []byte, error
+func (kind TokenKind) MarshalText() ([]byte, error) {
+	if _, exists := kindName(kind); !exists {
+		return nil, fmt.Errorf("cannot marshal unknown token kind %d", int(kind))
+	}
+	return []byte(kind.String()), nil
+}
+
+// UnmarshalText Decodes a token kind from its name.
+//
+// text - The name of the kind, as produced by MarshalText.
+// Return type: error
+func (kind *TokenKind) UnmarshalText(text []byte) error {
+	parsed, err := ParseTokenKind(string(text))
+	if err != nil {
+		return err
+	}
+	*kind = parsed
+	return nil
+}
+
+// ParseTokenKind Returns the token kind with the given name, including kinds added with RegisterTokenKind.
+//
+// name - The name of the kind, such as "let" or "open_paren".
+// Return type: TokenKind, error
+func ParseTokenKind(name string) (TokenKind, error) {
+	if kind, exists := kindsByName[name]; exists {
+		return kind, nil
+	}
+	if kind, exists := customKindByName(name); exists {
+		return kind, nil
+	}
+	return ILLEGAL, fmt.Errorf("unknown token kind %q", name)
+}
+
+// kindsByName Maps the name of every kind in tokenRegistry back to the kind.
+var kindsByName = func() map[string]TokenKind {
+	kinds := make(map[string]TokenKind, NUM_TOKENS)
+	for kind, info := range tokenRegistry {
+		kinds[info.name] = TokenKind(kind)
+	}
+	return kinds
+}()
+
+// kindName Returns the name of a kind from tokenRegistry or RegisterTokenKind.
+//
+// kind - The kind to look up.
+// Return type: string, bool (whether the kind is known)
+func kindName(kind TokenKind) (string, bool) {
+	if kind >= 0 && kind < NUM_TOKENS {
+		return tokenRegistry[kind].name, true
+	}
+	return customKindName(kind)
 }
 
 /*
-The `Token` struct in Go represents a token in the lexer. It has two fields:
+The `TokenFlags` type is a set of flags describing how a token was produced:
 
-- `Kind`: stores the kind of the token, which is an enum value from `TokenKind`.
-- `Value`: stores the literal value of the token, which is a string.
+- `IMPLICIT`: the token does not appear in the source code. The lexer inserted it, like the semicolon at the end of a line.
+- `RAW`: the STRING token is a raw string written with backticks, so its value was not unescaped.
+- `INDENTED`: the raw STRING token is a ``` block whose common indentation was stripped.
+*/
+type TokenFlags uint8
 
-The `Token` struct does not have any methods.
+const (
+	IMPLICIT TokenFlags = 1 << iota
+	RAW
+	INDENTED
+)
+
+/*
+The `Token` struct in Go represents a token in the lexer. It has seven fields:
+
+- `Kind`: stores the kind of the token, which is an enum value from `TokenKind`.
+- `Value`: stores the literal value of the token, which is a string. For strings this is the decoded value, with escape sequences resolved and quotes removed.
+- `Text`: stores the source text of the token exactly as written, so formatters can preserve the original spelling.
+- `Span`: stores where the token was found in the source code (file, byte offsets, line and column).
+- `Leading`: stores the whitespace and comments before the token, when the lexer preserves trivia.
+- `Trailing`: stores the whitespace and comments after the token on the same line, when the lexer preserves trivia.
+- `Flags`: stores extra information about the token, such as whether it was inserted by the lexer.
 */
 type Token struct {
-	Kind  TokenKind
-	Value string
+	Kind     TokenKind
+	Value    string
+	Text     string
+	Span     Span
+	Leading  []Trivia
+	Trailing []Trivia
+	Flags    TokenFlags
+}
+
+// Is Reports whether the token has all of the given flags.
+//
+// flags - The flags to check.
+// Return type: bool
+func (token Token) Is(flags TokenFlags) bool {
+	return token.Flags&flags == flags
 }
 
 // isOneOfMany Checks if the token kind is one of the expected tokens.
@@ -129,11 +371,12 @@ func (tk Token) isOneOfMany(expectedTokens ...TokenKind) bool {
 
 // Debug Prints a debug representation of the token.
 //
-// It prints the token kind and its literal value if it's an identifier, number, or string.
+// It prints the token kind and its literal value if it's an identifier, number, string,
+// doc comment or illegal token.
 // Otherwise, it only prints the token kind.
 // Return type: No return value.
 func (token Token) Debug() {
-	if token.isOneOfMany(IDENTIFIER, NUMBER, STRING) {
+	if token.isOneOfMany(IDENTIFIER, INT, FLOAT, STRING, TEMPLATE_HEAD, TEMPLATE_MIDDLE, TEMPLATE_TAIL, ILLEGAL, DOC_COMMENT) {
 		fmt.Printf("%s (%s)\n", TokenKindString(token.Kind), token.Value)
 	} else {
 		fmt.Printf("%s ()\n", TokenKindString(token.Kind))
@@ -148,122 +391,32 @@ func (token Token) Debug() {
 
 func NewToken(kind TokenKind, value string) Token {
 	return Token{
-		kind, value,
+		Kind:  kind,
+		Value: value,
 	}
-	// TokenKindString Returns a string representation of a TokenKind.
-	//
-	// kind - The TokenKind to be converted to a string.
-	// Return type: string
 }
+
+// NewTokenAt Creates a new token with the specified kind, value and source span.
+//
+// kind - The type of token to be created.
+// value - The value of the token to be created.
+// span - The region of source code the token was read from.
+// Return type: Token
+func NewTokenAt(kind TokenKind, value string, span Span) Token {
+	return Token{
+		Kind:  kind,
+		Value: value,
+		Span:  span,
+	}
+}
+
+// TokenKindString Returns a string representation of a TokenKind.
+//
+// kind - The TokenKind to be converted to a string.
+// Return type: string
 func TokenKindString(kind TokenKind) string {
-	switch kind {
-	case EOF:
-		return "eof"
-	case NULL:
-		return "null"
-	case NUMBER:
-		return "number"
-	case STRING:
-		return "string"
-	case TRUE:
-		return "true"
-	case FALSE:
-		return "false"
-	case IDENTIFIER:
-		return "identifier"
-	case OPEN_BRACKET:
-		return "open_bracket"
-	case CLOSE_BRACKET:
-		return "close_bracket"
-	case OPEN_CURLY:
-		return "open_curly"
-	case CLOSE_CURLY:
-		return "close_curly"
-	case OPEN_PAREN:
-		return "open_paren"
-	case CLOSE_PAREN:
-		return "close_paren"
-	case ASSIGNMENT:
-		return "assignment"
-	case EQUALS:
-		return "equals"
-	case NOT_EQUALS:
-		return "not_equals"
-	case NOT:
-		return "not"
-	case LESS:
-		return "less"
-	case LESS_EQUALS:
-		return "less_equals"
-	case GREATER:
-		return "greater"
-	case GREATER_EQUALS:
-		return "greater_equals"
-	case OR:
-		return "or"
-	case AND:
-		return "and"
-	case DOT:
-		return "dot"
-	case DOT_DOT:
-		return "dot_dot"
-	case SEMI_COLON:
-		return "semi_colon"
-	case COLON:
-		return "colon"
-	case QUESTION:
-		return "question"
-	case COMMA:
-		return "comma"
-	case PLUS_PLUS:
-		return "plus_plus"
-	case MINUS_MINUS:
-		return "minus_minus"
-	case PLUS_EQUALS:
-		return "plus_equals"
-	case MINUS_EQUALS:
-		return "minus_equals"
-	case NULLISH_ASSIGNMENT:
-		return "nullish_assignment"
-	case PLUS:
-		return "plus"
-	case DASH:
-		return "dash"
-	case SLASH:
-		return "slash"
-	case STAR:
-		return "star"
-	case PERCENT:
-		return "percent"
-	case LET:
-		return "let"
-	case CONST:
-		return "const"
-	case CLASS:
-		return "class"
-	case NEW:
-		return "new"
-	case IMPORT:
-		return "import"
-	case FROM:
-		return "from"
-	case FN:
-		return "fn"
-	case IF:
-		return "if"
-	case ELSE:
-		return "else"
-	case FOREACH:
-		return "foreach"
-	case FOR:
-		return "for"
-	case WHILE:
-		return "while"
-	case EXPORT:
-		return "export"
-	case IN:
-		return "in"
-	default:
-		return fmt.Sprintf("unknown(%d)", kind)
+	if name, exists := kindName(kind); exists {
+		return name
 	}
+	return fmt.Sprintf("unknown(%d)", kind)
 }
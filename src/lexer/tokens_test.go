@@ -0,0 +1,60 @@
+package lexer
+
+import "testing"
+
+// TestTokenRegistryCoversAllKinds Checks that every kind up to NUM_TOKENS has a unique name,
+// a category and a spelling that lexes back to the same kind, or to an identifier for contextual keywords.
+func TestTokenRegistryCoversAllKinds(t *testing.T) {
+	seen := map[string]TokenKind{}
+	for kind := TokenKind(0); kind < NUM_TOKENS; kind++ {
+		name := kind.String()
+		if name == "" || name == TokenKindString(-1) {
+			t.Errorf("kind %d has no name", int(kind))
+			continue
+		}
+		if other, exists := seen[name]; exists {
+			t.Errorf("kinds %d and %d are both named %q", int(other), int(kind), name)
+		}
+		seen[name] = kind
+
+		if kind.Category() == NO_CATEGORY {
+			t.Errorf("kind %s has no category", name)
+		}
+
+		parsed, err := ParseTokenKind(name)
+		if err != nil || parsed != kind {
+			t.Errorf("ParseTokenKind(%q) = %s, %v, want %s", name, parsed, err, name)
+		}
+
+		text, err := kind.MarshalText()
+		if err != nil {
+			t.Errorf("%s.MarshalText() failed: %v", name, err)
+			continue
+		}
+		var decoded TokenKind
+		if err := decoded.UnmarshalText(text); err != nil || decoded != kind {
+			t.Errorf("UnmarshalText(%q) = %s, %v, want %s", text, decoded, err, name)
+		}
+
+		if spelling := kind.Spelling(); spelling != "" {
+			want := kind
+			if kind.Category() == CONTEXTUAL_KEYWORD_TOKEN {
+				want = IDENTIFIER
+			}
+			tokens, errs := TokenizeWithErrors(spelling)
+			if len(errs) > 0 || len(tokens) < 2 || tokens[0].Kind != want || !(tokens[1].Kind == EOF || tokens[1].Is(IMPLICIT)) {
+				t.Errorf("spelling %q of %s does not lex as %s: %v %v", spelling, name, want, tokens, errs)
+			}
+		}
+	}
+}
+
+// TestParseTokenKindUnknown Checks that unknown names and kinds are rejected.
+func TestParseTokenKindUnknown(t *testing.T) {
+	if _, err := ParseTokenKind("no_such_kind"); err == nil {
+		t.Error("ParseTokenKind accepted an unknown name")
+	}
+	if _, err := (NUM_TOKENS + 1000).MarshalText(); err == nil {
+		t.Error("MarshalText accepted an unknown kind")
+	}
+}
@@ -0,0 +1,122 @@
+package lexer
+
+import "strings"
+
+type TriviaKind int
+
+const (
+	WHITESPACE_TRIVIA    TriviaKind = iota // spaces, tabs and other blanks
+	NEWLINE_TRIVIA                         // \n, \r\n or a lone \r
+	LINE_COMMENT_TRIVIA                    // // comment
+	BLOCK_COMMENT_TRIVIA                   // /* comment */
+)
+
+/*
+The `Trivia` struct in Go represents source text that is not part of any token. It has three fields:
+
+- `Kind`: stores what sort of trivia it is, which is an enum value from `TriviaKind`.
+- `Text`: stores the source text exactly as written.
+- `Span`: stores where the trivia was found in the source code.
+
+Trivia is only recorded when the lexer runs with `PreserveTrivia`.
+*/
+type Trivia struct {
+	Kind TriviaKind
+	Text string
+	Span Span
+}
+
+// TokenizeWithTrivia Tokenizes the source code, keeping whitespace and comments as token trivia.
+// Concatenating the FullText of every token reproduces the source byte for byte.
+//
+// source - The source code to be tokenized.
+// Return type: []Token, []error
+func TokenizeWithTrivia(source string) ([]Token, []error) {
+	lex := createLexer([]byte(source))
+	lex.PreserveTrivia()
+	tokens := make([]Token, 0, len(source)/8)
+	for {
+		token := lex.Next()
+		tokens = append(tokens, token)
+		if token.Kind == EOF {
+			return tokens, lex.Errors()
+		}
+	}
+}
+
+// PreserveTrivia Makes the lexer keep whitespace and comments instead of discarding them.
+//
+// Trivia on the same line after a token, up to but not including the newline, is attached
+// to that token as trailing trivia. Everything else is attached to the following token as
+// leading trivia, and whatever is left at the end of the source belongs to the EOF token.
+// A block comment spanning lines ends the line like a newline does, so when a semicolon is
+// inserted for it, the comment leads the implicit semicolon rather than trailing the token.
+// It must be called before the first token is read.
+//
+// No return value.
+func (lex *Lexer) PreserveTrivia() {
+	lex.keepTrivia = true
+}
+
+// FullText Returns the token's text together with its leading and trailing trivia.
+//
+// Return type: string
+func (token Token) FullText() string {
+	var text strings.Builder
+	for _, trivia := range token.Leading {
+		text.WriteString(trivia.Text)
+	}
+	text.WriteString(token.Text)
+	for _, trivia := range token.Trailing {
+		text.WriteString(trivia.Text)
+	}
+	return text.String()
+}
+
+// addTrivia Records the source code since start as trivia of the given kind.
+// It does nothing unless the lexer is preserving trivia.
+//
+// kind - The kind of trivia.
+// start - The position where the trivia begins.
+// No return value.
+func (lex *Lexer) addTrivia(kind TriviaKind, start Position) {
+	if !lex.keepTrivia {
+		return
+	}
+	lex.trivia = append(lex.trivia, Trivia{Kind: kind, Text: lex.textFrom(start.Offset), Span: lex.spanFrom(start)})
+}
+
+// scanTrailingTrivia Scans the whitespace and comments following the last token on the same line
+// and attaches them to it as trailing trivia.
+//
+// No return value.
+func (lex *Lexer) scanTrailingTrivia() {
+	for !lex.at_eof() {
+		c := lex.at()
+		if isNewline(c) {
+			break
+		}
+
+		switch {
+		case isSpace(c):
+			skipHandler(lex)
+		case c == '/' && lex.peek(1) == '/' && !lex.atDocComment():
+			commentHandler(lex)
+		case c == '/' && lex.peek(1) == '*':
+			blockCommentHandler(lex)
+		default:
+			lex.attachTrivia()
+			return
+		}
+	}
+	lex.attachTrivia()
+}
+
+// attachTrivia Moves the pending trivia onto the last queued token as trailing trivia.
+//
+// No return value.
+func (lex *Lexer) attachTrivia() {
+	last := &lex.queue[len(lex.queue)-1]
+	last.Trailing = append(last.Trailing, lex.trivia...)
+	lex.trivia = nil
+}
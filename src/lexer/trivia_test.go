@@ -0,0 +1,80 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// triviaString Formats trivia as a one-letter kind followed by the quoted text: w for
+// whitespace, n for newlines, c for line comments and b for block comments.
+func triviaString(trivia []Trivia) string {
+	parts := make([]string, len(trivia))
+	for i, t := range trivia {
+		parts[i] = fmt.Sprintf("%c%q", "wncb"[t.Kind], t.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// tokenTriviaString Formats a token as its leading trivia, its text in brackets and its trailing
+// trivia. Implicit semicolons are written as [⏎] and the end of the file as [eof].
+func tokenTriviaString(token Token) string {
+	text := token.Text
+	switch {
+	case token.Kind == EOF:
+		text = "eof"
+	case token.Is(IMPLICIT):
+		text = "⏎"
+	}
+
+	parts := []string{}
+	if len(token.Leading) > 0 {
+		parts = append(parts, triviaString(token.Leading))
+	}
+	parts = append(parts, "["+text+"]")
+	if len(token.Trailing) > 0 {
+		parts = append(parts, triviaString(token.Trailing))
+	}
+	return strings.Join(parts, " ")
+}
+
+// TestTriviaAttachment Checks which token every piece of whitespace and every comment is attached
+// to, and as leading or trailing trivia, following the rules documented on PreserveTrivia.
+func TestTriviaAttachment(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []string
+	}{
+		{"a // note\nb", []string{`[a] w" " c"// note"`, `[⏎]`, `n"\n" [b]`, `[⏎]`, `[eof]`}},
+		{"a /* x */ + b", []string{`[a] w" " b"/* x */" w" "`, `[+] w" "`, `[b]`, `[⏎]`, `[eof]`}},
+		{"a /* x\ny */ b\n", []string{`[a]`, `w" " b"/* x\ny */" [⏎] w" "`, `[b]`, `[⏎]`, `n"\n" [eof]`}},
+		{"a\rb", []string{`[a]`, `[⏎]`, `n"\r" [b]`, `[⏎]`, `[eof]`}},
+		{"a\r\n  b\r\n", []string{`[a]`, `[⏎]`, `n"\r\n" w"  " [b]`, `[⏎]`, `n"\r\n" [eof]`}},
+		{"\xEF\xBB\xBF#!/bin/run\nlet a", []string{`w"\ufeff" c"#!/bin/run" n"\n" [let] w" "`, `[a]`, `[⏎]`, `[eof]`}},
+		{"/// doc\nlet a;", []string{`[/// doc]`, `n"\n" [let] w" "`, `[a]`, `[;]`, `[eof]`}},
+		{"a /// doc\nb;", []string{`[a] w" "`, `[⏎]`, `[/// doc]`, `n"\n" [b]`, `[;]`, `[eof]`}},
+		{"a;\n\n// end\n", []string{`[a]`, `[;]`, `n"\n" n"\n" c"// end" n"\n" [eof]`}},
+		{"  \n", []string{`w"  " n"\n" [eof]`}},
+		{"", []string{`[eof]`}},
+	}
+
+	for _, test := range tests {
+		tokens, errs := TokenizeWithTrivia(test.source)
+		if len(errs) > 0 {
+			t.Errorf("lexing %q failed: %v", test.source, errs)
+		}
+
+		got := make([]string, len(tokens))
+		var full strings.Builder
+		for i, token := range tokens {
+			got[i] = tokenTriviaString(token)
+			full.WriteString(token.FullText())
+		}
+		if strings.Join(got, " | ") != strings.Join(test.want, " | ") {
+			t.Errorf("%q lexed as\n  %s\nwant\n  %s", test.source, strings.Join(got, " | "), strings.Join(test.want, " | "))
+		}
+		if full.String() != test.source {
+			t.Errorf("%q round-tripped as %q", test.source, full.String())
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package lexer
+
+import "testing"
+
+// TestUnicodeIdentifiers Checks which characters may start and continue an identifier, and that
+// other symbols lex as ILLEGAL without swallowing the identifiers around them.
+func TestUnicodeIdentifiers(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []string
+	}{
+		{"größe", []string{"identifier größe"}},
+		{"変数", []string{"identifier 変数"}},
+		{"e\u0301", []string{"identifier e\u0301"}},
+		{"\u00E9t\u00E9", []string{"identifier \u00E9t\u00E9"}},
+		{"Ⅻ", []string{"identifier Ⅻ"}},
+		{"a‿b", []string{"identifier a‿b"}},
+		{"a٣", []string{"identifier a٣"}},
+		{"𝑥𝑦", []string{"identifier 𝑥𝑦"}},
+		{"_x1", []string{"identifier _x1"}},
+		{"€", []string{"illegal €"}},
+		{"x€y", []string{"identifier x", "illegal €", "identifier y"}},
+		{"٣a", []string{"illegal ٣", "identifier a"}},
+		{"\u0301a", []string{"illegal \u0301", "identifier a"}},
+		{"😀", []string{"illegal 😀"}},
+	}
+
+	for _, test := range tests {
+		tokens, _ := TokenizeWithErrors(test.source)
+		got := []string{}
+		for _, token := range tokens {
+			if token.Kind != EOF && token.Kind != SEMI_COLON {
+				got = append(got, token.Kind.String()+" "+token.Text)
+			}
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("%q lexed as %q, want %q", test.source, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%q lexed as %q, want %q", test.source, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+// TestIdentClasses Checks isIdentStart and isIdentContinue for ASCII, letters from other scripts,
+// letter numbers, combining marks, digits, connector punctuation and symbols.
+func TestIdentClasses(t *testing.T) {
+	tests := []struct {
+		c      rune
+		start  bool
+		inside bool
+	}{
+		{'a', true, true},
+		{'Z', true, true},
+		{'_', true, true},
+		{'7', false, true},
+		{'$', false, false},
+		{'ß', true, true},
+		{'変', true, true},
+		{'𝑥', true, true},
+		{'Ⅻ', true, true},
+		{'℘', true, true},
+		{'\u0301', false, true},
+		{'\u0903', false, true},
+		{'٣', false, true},
+		{'‿', false, true},
+		{'\u00B7', false, true},
+		{'€', false, false},
+		{'😀', false, false},
+		{' ', false, false},
+	}
+
+	for _, test := range tests {
+		if got := isIdentStart(test.c); got != test.start {
+			t.Errorf("isIdentStart(%q) = %v, want %v", test.c, got, test.start)
+		}
+		if got := isIdentContinue(test.c); got != test.inside {
+			t.Errorf("isIdentContinue(%q) = %v, want %v", test.c, got, test.inside)
+		}
+	}
+}
+
+// TestUnicodeColumns Checks the rune and UTF-16 columns of tokens after BMP and astral
+// characters, and that both restart on the next line.
+func TestUnicodeColumns(t *testing.T) {
+	tokens, _ := TokenizeWithErrors("é 😀 𝑥 a\r\n  変 b")
+	tests := []struct {
+		text                      string
+		line, column, utf16Column int
+		endColumn, endUTF16Column int
+	}{
+		{"é", 1, 1, 1, 2, 2},
+		{"😀", 1, 3, 3, 4, 5},
+		{"𝑥", 1, 5, 6, 6, 8},
+		{"a", 1, 7, 9, 8, 10},
+		{"変", 2, 3, 3, 4, 4},
+		{"b", 2, 5, 5, 6, 6},
+	}
+
+	got := []Token{}
+	for _, token := range tokens {
+		if token.Text != "" {
+			got = append(got, token)
+		}
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("lexed %d tokens, want %d: %v", len(got), len(tests), got)
+	}
+	for i, test := range tests {
+		start, end := got[i].Span.Start, got[i].Span.End
+		if got[i].Text != test.text || start.Line != test.line || start.Column != test.column || start.UTF16Column != test.utf16Column || end.Column != test.endColumn || end.UTF16Column != test.endUTF16Column {
+			t.Errorf("%q is at %d:%d (UTF-16 %d) to column %d (UTF-16 %d), want %q at %d:%d (UTF-16 %d) to column %d (UTF-16 %d)",
+				got[i].Text, start.Line, start.Column, start.UTF16Column, end.Column, end.UTF16Column,
+				test.text, test.line, test.column, test.utf16Column, test.endColumn, test.endUTF16Column)
+		}
+	}
+}
@@ -1,17 +1,29 @@
 package main
 
 import (
+	"fmt"
 	"os"
+
+	"github.com/go-parser/src/ast"
 	// "github.com/go-parser/src/lexer"
 	"github.com/go-parser/src/parser"
 	"github.com/sanity-io/litter"
 )
 
 func main() {
-	bytes, _ := os.ReadFile("./examples/04.lang")
+	files := ast.NewFileSet()
+	file, err := files.ReadFile("./examples/04.lang")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	// tokens := lexer.Tokenize(string(bytes))
-	ast := parser.Parse(string(bytes))
-	litter.Dump(ast)
+	// tokens, err := lexer.TokenizeFile(file.Name, file.Contents)
+	program, errs := parser.ParseFile(file)
+	if len(errs) > 0 {
+		fmt.Fprintln(os.Stderr, errs)
+		os.Exit(1)
+	}
+	litter.Dump(program)
 
 }
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-parser/src/ast"
+)
+
+// concurrentFiles Returns a few hundred files built from the examples and from snippets with
+// errors, so concurrent parses exercise recovery as well as the happy path.
+func concurrentFiles(t *testing.T) []*ast.SourceFile {
+	sources := []string{
+		"let a = ;\nlet = 2\nconst c: = 3\n",
+		"let s = \"${a + `raw`}\" + ```\n  block\n  ```\n",
+		"import path from \"std/path\"\nlet from = -(1 + 2) * 3\n",
+	}
+	paths, err := filepath.Glob("../../examples/*.lang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		example, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, string(example))
+	}
+
+	set := ast.NewFileSet()
+	for i := 0; i < 300; i++ {
+		set.AddFile(fmt.Sprintf("file%d.lang", i), []byte(sources[i%len(sources)]))
+	}
+	return set.Files()
+}
+
+// TestParseConcurrently Parses hundreds of files from many goroutines at once. Run it with
+// `go test -race` to check that parsers share no mutable state.
+func TestParseConcurrently(t *testing.T) {
+	files := concurrentFiles(t)
+	blocks := make([]ast.BlockStmt, len(files))
+	errs := make([]ErrorList, len(files))
+
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		go func(i int, file *ast.SourceFile) {
+			defer wg.Done()
+			blocks[i], errs[i] = ParseFile(file)
+		}(i, file)
+	}
+	wg.Wait()
+
+	for i, file := range files {
+		block, fileErrs := ParseFile(file)
+		if !reflect.DeepEqual(blocks[i], block) || !reflect.DeepEqual(errs[i], fileErrs) {
+			t.Errorf("concurrent parse of %s differs from a sequential parse", file.Name)
+		}
+	}
+}
+
+// TestParseFiles Checks that ParseFiles returns the same results, in order, as parsing each file alone,
+// whatever the number of workers.
+func TestParseFiles(t *testing.T) {
+	files := concurrentFiles(t)
+	for _, workers := range []int{0, 1, 4, len(files) + 1} {
+		blocks, errs := ParseFiles(files, workers)
+		if len(blocks) != len(files) || len(errs) != len(files) {
+			t.Fatalf("ParseFiles with %d workers returned %d trees and %d error lists for %d files", workers, len(blocks), len(errs), len(files))
+		}
+		for i, file := range files {
+			block, fileErrs := ParseFile(file)
+			if !reflect.DeepEqual(blocks[i], block) || !reflect.DeepEqual(errs[i], fileErrs) {
+				t.Errorf("ParseFiles with %d workers: result %d differs from parsing %s alone", workers, i, file.Name)
+			}
+		}
+	}
+
+	if blocks, errs := ParseFiles(nil, 4); len(blocks) != 0 || len(errs) != 0 {
+		t.Errorf("ParseFiles(nil) returned %d trees and %d error lists", len(blocks), len(errs))
+	}
+}
@@ -0,0 +1,280 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-parser/src/ast"
+	"github.com/go-parser/src/lexer"
+)
+
+/*
+The `ErrorCode` type identifies the kind of problem an `Error` describes. Codes are stable,
+machine readable strings, so tools can react to a particular problem without matching on
+the wording of the message:
+
+- `LEX_ERROR`: the source could not be split into tokens, such as an unterminated string.
+- `EXPECTED_TOKEN`: a particular token, such as `;` or a variable name, was missing.
+- `EXPECTED_EXPRESSION`: an expression was missing.
+- `EXPECTED_TYPE`: a type was missing.
+- `UNEXPECTED_TOKEN`: a token appeared where the grammar does not allow it.
+- `MISSING_VALUE`: a declaration has neither a type nor a value, or a constant has no value.
+- `NESTED_TOO_DEEPLY`: expressions or types are nested deeper than `maxNestingDepth`.
+*/
+type ErrorCode string
+
+const (
+	LEX_ERROR           ErrorCode = "lex_error"
+	EXPECTED_TOKEN      ErrorCode = "expected_token"
+	EXPECTED_EXPRESSION ErrorCode = "expected_expression"
+	EXPECTED_TYPE       ErrorCode = "expected_type"
+	UNEXPECTED_TOKEN    ErrorCode = "unexpected_token"
+	MISSING_VALUE       ErrorCode = "missing_value"
+	NESTED_TOO_DEEPLY   ErrorCode = "nested_too_deeply"
+)
+
+/*
+This class definition defines a struct called `Error` in Go, which describes a syntax error found while parsing. Here's a succinct explanation of what each field does:
+
+* `Span lexer.Span`: the region of source code the error refers to, usually the token the parser could not handle.
+* `Code ErrorCode`: a machine readable identifier for the kind of problem.
+* `Message string`: a short, human readable description of the problem in source terms, such as "expected expression, found ';'".
+
+`Error` implements the `error` interface.
+*/
+type Error struct {
+	Span    lexer.Span
+	Code    ErrorCode
+	Message string
+}
+
+// Error Returns the error formatted as file:line:column: message.
+//
+// Return type: string
+func (err *Error) Error() string {
+	return fmt.Sprintf("%s: %s", err.Span, err.Message)
+}
+
+/*
+The `ErrorList` type holds the errors found while parsing, in source order. The parser recovers
+from each error and carries on, so one parse reports every problem it can find. `Parse`,
+`ParseReader` and `ParseFile` return an empty list when the source is valid.
+*/
+type ErrorList []*Error
+
+// Error Returns every error in the list, one per line.
+//
+// Return type: string
+func (list ErrorList) Error() string {
+	lines := make([]string, len(list))
+	for i, err := range list {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Err Returns the list as an error, or nil if it is empty, for callers that only need
+// to know whether parsing succeeded.
+//
+// Return type: error
+func (list ErrorList) Err() error {
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// maxNestingDepth Is how deeply expressions and types may be nested. Deeper input is
+// rejected with an error rather than risking a stack overflow, which Go cannot recover from.
+const maxNestingDepth = 1000
+
+/*
+This Go function, `fail`, abandons the current statement with a syntax error at the current
+token. The error is raised as a panic carrying an `*Error` and recovered by `recoverStmt`, which
+records it and resumes at the next statement, so the grammar functions do not have to thread
+errors through every call.
+*/
+func (p *parser) fail(code ErrorCode, format string, args ...any) {
+	p.failAt(p.currentToken().Span, code, format, args...)
+}
+
+/*
+This Go function, `failAt`, is `fail` for errors that belong to an earlier part of the
+source than the current token, such as the name of a constant declared without a value.
+*/
+func (p *parser) failAt(span lexer.Span, code ErrorCode, format string, args ...any) {
+	panic(&Error{Span: span, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+/*
+This Go function, `nest`, records that the parser descended into a nested expression or type
+and fails once `maxNestingDepth` is exceeded. The returned function undoes the step and is
+meant to be deferred. When it fails there is nothing to defer yet, so the step is undone first;
+otherwise every over-deep statement would leave the depth one higher for the rest of the file.
+*/
+func (p *parser) nest() func() {
+	p.depth++
+	if p.depth > maxNestingDepth {
+		p.depth--
+		p.fail(NESTED_TOO_DEEPLY, "expression nested too deeply")
+	}
+	return func() { p.depth-- }
+}
+
+// maxQuotedLength Is how much of a token's source text error messages quote before cutting it short.
+const maxQuotedLength = 20
+
+/*
+This Go function, `describeToken`, describes a token the way it appears in the source, for
+use after "found" in error messages: its text in quotes, "newline" for a semicolon the lexer
+inserted at the end of a line, and "end of file" at the end.
+*/
+func describeToken(token lexer.Token) string {
+	switch {
+	case token.Kind == lexer.EOF:
+		return "end of file"
+	case token.Is(lexer.IMPLICIT):
+		return "newline"
+	case token.Text == "":
+		return lexer.TokenKindString(token.Kind)
+	}
+
+	text := []rune(token.Text)
+	if len(text) > maxQuotedLength {
+		return fmt.Sprintf("'%s...'", string(text[:maxQuotedLength]))
+	}
+	return fmt.Sprintf("'%s'", string(text))
+}
+
+/*
+This Go function, `describeKind`, describes what a token of the given kind looks like, for
+use after "expected" in error messages: the quoted spelling of keywords and punctuation, or
+the name of the kind, such as "identifier", for tokens whose text varies.
+*/
+func describeKind(kind lexer.TokenKind) string {
+	if spelling := kind.Spelling(); spelling != "" {
+		return fmt.Sprintf("'%s'", spelling)
+	}
+	return lexer.TokenKindString(kind)
+}
+
+/*
+This Go function, `report`, records an error without stopping the parse. An error at the same
+position as one already reported is dropped: it is almost always a consequence of the first
+problem rather than a new one.
+*/
+func (p *parser) report(err *Error) {
+	if _, exists := p.reported[err.Span.Start.Offset]; exists {
+		return
+	}
+	p.reported[err.Span.Start.Offset] = struct{}{}
+	p.errors = append(p.errors, err)
+}
+
+/*
+This Go function, `reportLexErrors`, records the lexing errors found since it was last called.
+The lexer emits an ILLEGAL token for the offending text and carries on, so the parser does too.
+*/
+func (p *parser) reportLexErrors() {
+	errs := p.lex.Errors()
+	for _, err := range errs[p.lexErrors:] {
+		if err, ok := err.(*lexer.LexError); ok {
+			message := err.Message
+			if err.Text != "" {
+				message = fmt.Sprintf("%s %q", err.Message, err.Text)
+			}
+			p.report(&Error{Span: err.Span, Code: LEX_ERROR, Message: message})
+		}
+	}
+	p.lexErrors = len(errs)
+}
+
+/*
+This Go function, `recoverStmt`, is deferred by `parse_stmt` with the statement's first token and
+the number of braces open before it, and implements panic-mode recovery.
+When a statement fails, the `*Error` raised by `fail` is recorded, the tokens up to the next
+statement boundary are skipped with `synchronise`, and the statement is replaced by an
+`ast.BadStmt` covering the skipped source. Any other panic is a bug in the parser and is
+passed on untouched.
+*/
+func (p *parser) recoverStmt(start lexer.Token, curlies int, stmt *ast.Stmt) {
+	switch r := recover().(type) {
+	case nil:
+	case *Error:
+		p.report(r)
+		p.synchronise(start, curlies)
+		*stmt = ast.BadStmt{Span: lexer.Span{
+			File:  start.Span.File,
+			Start: start.Span.Start,
+			End:   p.currentToken().Span.Start,
+		}}
+	default:
+		panic(r)
+	}
+}
+
+/*
+This Go function, `synchronise`, skips tokens after a syntax error until parsing can safely
+resume: just past a `;`, or at a keyword that starts a statement. Braces opened by the failed
+statement, such as those of a keyed initialiser, are counted as they are skipped, so `;` and
+keywords inside them do not end the statement early. A statement keyword at the start of a line
+still does, so an unclosed `{` costs one statement rather than the rest of the file; the braces
+it leaves open are then forgotten. A `}` that closes an enclosing block is left for the block,
+and a stray `}` at the top level is skipped. It always skips at least one token when the failed
+statement started at the current token, so parsing makes progress whatever the input.
+*/
+func (p *parser) synchronise(start lexer.Token, curlies int) {
+	if p.currentToken().Span.Start.Offset == start.Span.Start.Offset && p.hasTokens() {
+		p.advance()
+	}
+
+	depth := p.curlies - curlies
+	defer func() { p.curlies = curlies }()
+
+	previous := start
+	for p.hasTokens() {
+		token := p.currentToken()
+		if _, exists := p.grammar.stmt_lu[token.Kind]; exists {
+			if depth == 0 || token.Span.Start.Line > previous.Span.End.Line {
+				return
+			}
+		}
+
+		switch token.Kind {
+		case lexer.OPEN_CURLY:
+			depth++
+		case lexer.CLOSE_CURLY:
+			if depth == 0 {
+				if curlies == 0 {
+					p.advance()
+				}
+				return
+			}
+			depth--
+		case lexer.SEMI_COLON:
+			if depth == 0 {
+				p.advance()
+				return
+			}
+		}
+
+		previous = token
+		p.advance()
+	}
+}
+
+/*
+This Go function, `bad_expr`, reports a missing or malformed expression at the current token
+and returns an `ast.BadExpr` in its place, so the enclosing statement can still be parsed. The
+token is left for the caller, except for ILLEGAL tokens, which the lexer has already reported.
+*/
+func (p *parser) bad_expr(code ErrorCode, format string, args ...any) ast.Expr {
+	token := p.currentToken()
+	if token.Kind == lexer.ILLEGAL {
+		p.advance()
+		return ast.BadExpr{Span: token.Span}
+	}
+
+	p.report(&Error{Span: token.Span, Code: code, Message: fmt.Sprintf(format, args...)})
+	return ast.BadExpr{Span: lexer.Span{File: token.Span.File, Start: token.Span.Start, End: token.Span.Start}}
+}
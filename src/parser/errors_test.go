@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-parser/src/ast"
+)
+
+// TestParseErrors Checks the code, message and position reported for common syntax errors.
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		code    ErrorCode
+		message string
+		at      string
+	}{
+		{"let a = ;", EXPECTED_EXPRESSION, "expected expression, found ';'", "1:9"},
+		{"let a = 1 +\n", EXPECTED_EXPRESSION, "expected expression, found end of file", "2:1"},
+		{"let = 1;", EXPECTED_TOKEN, "expected variable name, found '='", "1:5"},
+		{"let a = (1 + 2;", EXPECTED_TOKEN, "expected ')', found ';'", "1:15"},
+		{"let a: = 1;", EXPECTED_TYPE, "expected type, found '='", "1:8"},
+		{"let a;", MISSING_VALUE, "variable 'a' needs a type or a value", "1:5"},
+		{"const b: number;", MISSING_VALUE, "constant 'b' needs a value", "1:7"},
+		{"import fs from path;", EXPECTED_TOKEN, "expected module path string, found 'path'", "1:16"},
+		{"f(a b);", EXPECTED_TOKEN, "expected ',' or ')', found 'b'", "1:5"},
+		{"f(a,", EXPECTED_TOKEN, "expected ')', found end of file", "1:5"},
+		{"let a = f(1\nlet b = 2\nlet c = 3\nlet d = 4", EXPECTED_TOKEN, "expected ',' or ')', found 'let'", "2:1"},
+		{"a.;", EXPECTED_TOKEN, "expected property name, found ';'", "1:3"},
+		{"a[1;", EXPECTED_TOKEN, "expected ']', found ';'", "1:4"},
+		{"let a = [1 2];", EXPECTED_TOKEN, "expected ',' or ']', found '2'", "1:12"},
+		{"let a = [...];", EXPECTED_EXPRESSION, "expected expression, found ']'", "1:13"},
+		{"new Point;", EXPECTED_TOKEN, "expected '(' or '{' after type in new expression, found ';'", "1:10"},
+		{"new Point { x 1 };", EXPECTED_TOKEN, "expected ':', found '1'", "1:15"},
+		{"new Point {\n  x: 1\n  y: 2\n};", EXPECTED_TOKEN, "expected ',' or '}', found 'y'", "3:3"},
+		{"let m: Map<string number>;", EXPECTED_TOKEN, "expected ',' or '>', found 'number'", "1:19"},
+		{"let m: fs.;", EXPECTED_TOKEN, "expected type name, found ';'", "1:11"},
+		{"let a = \"abc", LEX_ERROR, "unterminated string literal", "1:9"},
+		{"let s = 1 \"this string is far too long to quote\";", EXPECTED_TOKEN, "expected ';', found '\"this string is far ...'", "1:11"},
+		{strings.Repeat("(", maxNestingDepth+1), NESTED_TOO_DEEPLY, "expression nested too deeply", "1:1001"},
+	}
+
+	for _, test := range tests {
+		_, errs := Parse(test.source)
+		if len(errs) != 1 {
+			t.Errorf("Parse(%q) returned %d errors, want 1: %v", test.source, len(errs), errs)
+			continue
+		}
+		err := errs[0]
+		if err.Code != test.code || !strings.HasPrefix(err.Message, test.message) || err.Span.String() != test.at {
+			t.Errorf("Parse(%q) = %s %s: %s, want %s %s: %s", test.source, err.Code, err.Span, err.Message, test.code, test.at, test.message)
+		}
+	}
+}
+
+// TestParseValidSource Checks that valid source returns an empty error list.
+func TestParseValidSource(t *testing.T) {
+	_, errs := Parse("let a = 1;\nconst b: number = a * 2\nlet m: Map<a\n, b> = 1\n")
+	if len(errs) != 0 || errs.Err() != nil {
+		t.Errorf("Parse returned errors for valid source: %v", errs)
+	}
+}
+
+// TestParseRecovers Checks that the parser reports every error in a file and keeps the statements around them.
+func TestParseRecovers(t *testing.T) {
+	source := "let a = ;\nlet = 2\nconst c: = 3\nlet d = @ + 1\nlet e = 1 2\nlet ok = 5 * 2\n"
+	block, errs := Parse(source)
+
+	wantErrors := []string{"1:9", "2:5", "3:10", "4:9", "5:11"}
+	if len(errs) != len(wantErrors) {
+		t.Fatalf("Parse returned %d errors, want %d:\n%v", len(errs), len(wantErrors), errs)
+	}
+	for i, at := range wantErrors {
+		if errs[i].Span.String() != at {
+			t.Errorf("error %d is at %s, want %s: %v", i, errs[i].Span, at, errs[i])
+		}
+	}
+
+	if len(block.Body) != 6 {
+		t.Fatalf("Parse returned %d statements, want 6: %#v", len(block.Body), block.Body)
+	}
+	if decl, ok := block.Body[0].(ast.VarDeclStmt); !ok {
+		t.Errorf("statement 0 is %T, want ast.VarDeclStmt", block.Body[0])
+	} else if _, ok := decl.AssignedValue.(ast.BadExpr); !ok {
+		t.Errorf("value of statement 0 is %T, want ast.BadExpr", decl.AssignedValue)
+	}
+	for _, i := range []int{1, 2, 4} {
+		if _, ok := block.Body[i].(ast.BadStmt); !ok {
+			t.Errorf("statement %d is %T, want ast.BadStmt", i, block.Body[i])
+		}
+	}
+	if decl, ok := block.Body[5].(ast.VarDeclStmt); !ok || decl.VariableName != "ok" {
+		t.Errorf("statement 5 is %#v, want the declaration of ok", block.Body[5])
+	}
+}
+
+// TestParseRecoversFromBraces Checks that recovery skips a failed initialiser as a whole, but that
+// an unclosed one only costs its own statement.
+func TestParseRecoversFromBraces(t *testing.T) {
+	tests := []struct {
+		source     string
+		errors     int
+		statements int
+		ok         int
+	}{
+		{"new Point { x 1; y: 2 };\nlet ok = 1", 1, 2, 1},
+		{"let p = new Point { x: 1, y: { let } }\nlet ok = 1", 1, 2, 1},
+		{"let p = new Point { x: 1\nlet a = ;\nlet b = ;\nlet c = ;\nlet ok = 1", 4, 5, 4},
+		{"let p = new Point { x: 1 let a = 2 }\nlet ok = 1", 1, 2, 1},
+		{"let p = new Point { x: 1\nlet ok = 1\n}", 2, 3, 1},
+	}
+
+	for _, test := range tests {
+		block, errs := Parse(test.source)
+		if len(errs) != test.errors || len(block.Body) != test.statements {
+			t.Errorf("Parse(%q) returned %d statements and %d errors, want %d and %d: %v", test.source, len(block.Body), len(errs), test.statements, test.errors, errs)
+			continue
+		}
+		if decl, ok := block.Body[test.ok].(ast.VarDeclStmt); !ok || decl.VariableName != "ok" {
+			t.Errorf("Parse(%q): statement %d is %#v, want the declaration of ok", test.source, test.ok, block.Body[test.ok])
+		}
+	}
+}
+
+// TestParseNestingDepthRecovers Checks that statements nested too deeply do not leave the nesting
+// depth raised, so valid statements after any number of them still parse.
+func TestParseNestingDepthRecovers(t *testing.T) {
+	tooDeep := strings.Repeat("(", maxNestingDepth+1) + ";\n"
+	block, errs := Parse(strings.Repeat(tooDeep, maxNestingDepth+1) + "let ok = " + strings.Repeat("(", maxNestingDepth-1) + "1" + strings.Repeat(")", maxNestingDepth-1))
+
+	if len(errs) != maxNestingDepth+1 {
+		t.Fatalf("Parse returned %d errors, want %d", len(errs), maxNestingDepth+1)
+	}
+	for _, err := range errs {
+		if err.Code != NESTED_TOO_DEEPLY {
+			t.Fatalf("Parse returned %s, want only %s errors", err, NESTED_TOO_DEEPLY)
+		}
+	}
+	if decl, ok := block.Body[len(block.Body)-1].(ast.VarDeclStmt); !ok || decl.VariableName != "ok" {
+		t.Errorf("last statement is %T, want the declaration of ok", block.Body[len(block.Body)-1])
+	}
+}
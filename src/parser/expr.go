@@ -1,7 +1,7 @@
 package parser
 
 import (
-	"fmt"
+	"math/big"
 	"strconv"
 
 	"github.com/go-parser/src/ast"
@@ -19,7 +19,7 @@ This is a recursive descent parser function in Go, specifically designed to pars
 
 * It takes a `parser` instance (`p`) and a `binding_power` (`bp`) as inputs.
 * It first parses the "NUD" (Null denotation, or prefix operator) of the current token.
-* If a NUD handler is found, it calls the handler to parse the expression.
+* If a NUD handler is found, it calls the handler to parse the expression. Otherwise it reports an "expected expression" error and carries on with an `ast.BadExpr`.
 * Then, it enters a loop where it checks if the current token's binding power is greater than the input `bp`.
 * If it is, it parses the "LED" (Left denotation, or infix operator) of the current token.
 * If an LED handler is found, it calls the handler to parse the expression, passing the current parser, the left-hand side of the expression, and the binding power of the current token.
@@ -29,25 +29,28 @@ This is a recursive descent parser function in Go, specifically designed to pars
 In essence, this function is responsible for parsing expressions with prefix and infix operators, using a recursive descent approach.
 */
 func parse_expr(p *parser, bp binding_power) ast.Expr {
+	defer p.nest()()
+
 	// First parse the NUD
 	tokenKind := p.currentTokenKind()
-	nud_fn, exists := nud_lu[tokenKind]
+	nud_fn, exists := p.grammar.nud_lu[tokenKind]
 
-	if !exists {
-		panic(fmt.Sprintf("NUD handler expected for token %s\n", lexer.TokenKindString(tokenKind)))
+	var left ast.Expr
+	if exists {
+		left = nud_fn(p)
+	} else {
+		left = p.bad_expr(EXPECTED_EXPRESSION, "expected expression, found %s", describeToken(p.currentToken()))
 	}
 
-	left := nud_fn(p)
-
-	for bp_lu[p.currentTokenKind()] > bp {
+	for p.grammar.bp_lu[p.currentTokenKind()] > bp {
 		tokenKind = p.currentTokenKind()
-		led_fn, exists := led_lu[tokenKind]
+		led_fn, exists := p.grammar.led_lu[tokenKind]
 
 		if !exists {
-			panic(fmt.Sprintf("LED handler expected for token %s\n", lexer.TokenKindString(tokenKind)))
+			p.fail(UNEXPECTED_TOKEN, "unexpected %s in expression", describeToken(p.currentToken()))
 		}
 
-		left = led_fn(p, left, bp_lu[p.currentTokenKind()])
+		left = led_fn(p, left, p.grammar.bp_lu[p.currentTokenKind()])
 	}
 
 	return left
@@ -59,33 +62,109 @@ func parse_expr(p *parser, bp binding_power) ast.Expr {
 // Return type: ast.Expr
 
 /*
-This function, `parse_primary_expr`, parses a primary expression from the current token in the parser. It handles three types of primary expressions:
+This function, `parse_primary_expr`, parses a primary expression from the current token in the parser. It handles these types of primary expressions:
 
-*   `lexer.NUMBER`: Parses a number literal and returns an `ast.NumberExpr` with the parsed float value.
-*   `lexer.STRING`: Parses a string literal and returns an `ast.StringExpr` with the string value.
+*   `lexer.INT`: Parses an integer literal and returns an `ast.IntegerExpr` with the exact value.
+*   `lexer.FLOAT`: Parses a floating-point literal and returns an `ast.NumberExpr` with the parsed float value.
+*   `lexer.STRING`: Parses a quoted or raw string literal and returns an `ast.StringExpr` with the string value and the form it was written in.
+*   `lexer.TRUE` and `lexer.FALSE`: Parses a boolean literal and returns an `ast.BooleanExpr`.
+*   `lexer.NULL`: Parses the null literal and returns an `ast.NullExpr`.
 *   `lexer.IDENTIFIER`: Parses an identifier and returns an `ast.SymbolExpr` with the identifier's value.
 
-If the current token is none of the above, it panics with an error message indicating an unexpected token.
+If the current token is none of the above, it reports an "expected expression" error naming the token found instead and returns an `ast.BadExpr`.
 */
 func parse_primary_expr(p *parser) ast.Expr {
 	switch p.currentTokenKind() {
-	case lexer.NUMBER:
+	case lexer.INT:
+		return ast.IntegerExpr{
+			Value: parse_int_literal(p.advance().Value),
+		}
+
+	case lexer.FLOAT:
 		number, _ := strconv.ParseFloat(p.advance().Value, 64)
 		return ast.NumberExpr{
 			Value: number,
 		}
 
 	case lexer.STRING:
+		token := p.advance()
+		form := ast.QUOTED_STRING
+		if token.Is(lexer.INDENTED) {
+			form = ast.INDENTED_STRING
+		} else if token.Is(lexer.RAW) {
+			form = ast.RAW_STRING
+		}
 		return ast.StringExpr{
-			Value: p.advance().Value,
+			Value: token.Value,
+			Form:  form,
+		}
+
+	case lexer.TRUE, lexer.FALSE:
+		return ast.BooleanExpr{
+			Value: p.advance().Kind == lexer.TRUE,
 		}
 
+	case lexer.NULL:
+		p.advance()
+		return ast.NullExpr{}
+
 	case lexer.IDENTIFIER:
 		return ast.SymbolExpr{
 			Value: p.advance().Value,
 		}
 	default:
-		panic(fmt.Sprintf("Parser:Error -> Unexpected token %s\n", lexer.TokenKindString(p.currentTokenKind())))
+		return p.bad_expr(EXPECTED_EXPRESSION, "expected expression, found %s", describeToken(p.currentToken()))
+	}
+}
+
+/*
+This function, `parse_int_literal`, converts the value of an INT token into a `*big.Int`.
+The lexer has already validated the literal and removed any '_' separators, so only the
+optional 0x, 0o or 0b prefix has to be taken into account.
+*/
+func parse_int_literal(literal string) *big.Int {
+	base := 10
+	if len(literal) > 2 && literal[0] == '0' {
+		switch literal[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+	}
+	if base != 10 {
+		literal = literal[2:]
+	}
+
+	value, _ := new(big.Int).SetString(literal, base)
+	return value
+}
+
+/*
+This function, `parse_template_expr`, parses an interpolated string. The lexer splits a
+template into a TEMPLATE_HEAD token, the tokens of each embedded expression, TEMPLATE_MIDDLE
+tokens between expressions and a closing TEMPLATE_TAIL token. The function collects the
+literal values of those tokens and parses every embedded expression, returning an
+`ast.TemplateExpr`.
+*/
+func parse_template_expr(p *parser) ast.Expr {
+	literals := []string{p.advance().Value}
+	expressions := []ast.Expr{}
+
+	for {
+		expressions = append(expressions, parse_expr(p, default_bp))
+		if p.currentTokenKind() != lexer.TEMPLATE_MIDDLE {
+			break
+		}
+		literals = append(literals, p.advance().Value)
+	}
+	literals = append(literals, p.expect(lexer.TEMPLATE_TAIL).Value)
+
+	return ast.TemplateExpr{
+		Literals:    literals,
+		Expressions: expressions,
 	}
 }
 
@@ -176,6 +255,163 @@ syntax tree (AST) using a parser object.
 func parse_grouping_expr(p *parser) ast.Expr {
 	p.advance() // advance past grouping start
 	expression := parse_expr(p, default_bp)
+	p.skipImplicitSemicolons()
 	p.expect(lexer.CLOSE_PAREN) // advance past close
 	return expression
 }
+
+/*
+This function, `parse_call_expr`, is the left-denotation handler of `(` and parses a function
+call on the expression to its left, so calls chain onto any expression, as in `getFn()(x)`.
+It returns an `ast.CallExpr` holding the callee, the arguments and the span of the parentheses.
+*/
+func parse_call_expr(p *parser, left ast.Expr, bp binding_power) ast.Expr {
+	arguments, parens := parse_argument_list(p)
+	return ast.CallExpr{
+		Callee:    left,
+		Arguments: arguments,
+		Parens:    parens,
+	}
+}
+
+/*
+This function, `parse_argument_list`, parses a parenthesised argument list for calls and
+constructor calls. Arguments are separated by commas and a trailing comma before `)` is
+allowed. The lexer inserts a semicolon after an argument that ends a line, so one argument per
+line works; those are skipped. It returns the arguments, empty but not nil when there are none,
+and the span from the opening to the closing parenthesis.
+*/
+func parse_argument_list(p *parser) ([]ast.Expr, lexer.Span) {
+	openParen := p.expect(lexer.OPEN_PAREN)
+	arguments := []ast.Expr{}
+
+	for p.hasTokens() && p.currentTokenKind() != lexer.CLOSE_PAREN {
+		arguments = append(arguments, parse_expr(p, default_bp))
+
+		p.skipImplicitSemicolons()
+		if p.currentTokenKind() != lexer.CLOSE_PAREN {
+			p.expectError(lexer.COMMA, "',' or ')'")
+		}
+	}
+
+	closeParen := p.expect(lexer.CLOSE_PAREN)
+	return arguments, lexer.Span{File: openParen.Span.File, Start: openParen.Span.Start, End: closeParen.Span.End}
+}
+
+/*
+This function, `parse_member_expr`, is the left-denotation handler of `.` and parses a member
+access such as `fs.stat(file).creationTime`. The property must be a name; contextual keywords
+such as `in` reach the parser as identifiers, so they are valid property names too. It returns
+an `ast.MemberExpr`.
+*/
+func parse_member_expr(p *parser, left ast.Expr, bp binding_power) ast.Expr {
+	p.expect(lexer.DOT)
+	property := p.expectError(lexer.IDENTIFIER, "property name")
+
+	return ast.MemberExpr{
+		Object:   left,
+		Property: property.Value,
+	}
+}
+
+/*
+This function, `parse_computed_expr`, is the left-denotation handler of `[` and parses an index
+expression such as `array[index]`. Any expression may appear inside the brackets. It returns an
+`ast.ComputedExpr`.
+*/
+func parse_computed_expr(p *parser, left ast.Expr, bp binding_power) ast.Expr {
+	p.expect(lexer.OPEN_BRACKET)
+	index := parse_expr(p, default_bp)
+	p.skipImplicitSemicolons()
+	p.expect(lexer.CLOSE_BRACKET)
+
+	return ast.ComputedExpr{
+		Object: left,
+		Index:  index,
+	}
+}
+
+/*
+This function, `parse_array_literal_expr`, is the null-denotation handler of `[` and parses an
+array literal such as `[]`, `[1, 2, 3,]` or `[...a, b]`. Elements are separated by commas and a
+trailing comma before `]` is allowed, and implicit semicolons after elements that end a line are
+skipped as in `parse_argument_list`. An element written `...expr` spreads another array into
+this one and is returned as an `ast.SpreadExpr`. It returns an `ast.ArrayLiteral`, matching the
+`[]T` types parsed by `parse_array_type`.
+*/
+func parse_array_literal_expr(p *parser) ast.Expr {
+	p.expect(lexer.OPEN_BRACKET)
+	elements := []ast.Expr{}
+
+	for p.hasTokens() && p.currentTokenKind() != lexer.CLOSE_BRACKET {
+		if p.currentTokenKind() == lexer.ELLIPSIS {
+			p.advance()
+			elements = append(elements, ast.SpreadExpr{
+				Argument: parse_expr(p, default_bp),
+			})
+		} else {
+			elements = append(elements, parse_expr(p, default_bp))
+		}
+
+		p.skipImplicitSemicolons()
+		if p.currentTokenKind() != lexer.CLOSE_BRACKET {
+			p.expectError(lexer.COMMA, "',' or ']'")
+		}
+	}
+
+	p.expect(lexer.CLOSE_BRACKET)
+	return ast.ArrayLiteral{
+		Elements: elements,
+	}
+}
+
+/*
+This function, `parse_new_expr`, is the null-denotation handler of `new` and parses an object
+instantiation. The type is parsed with `parse_type`, so qualified and generic types such as
+`new fs.Reader()` or `new Map<string, number>()` work, and is followed by either:
+
+*   a constructor call `(args)`, parsed like the arguments of a function call, or
+*   a keyed initialiser `{ field: value, ... }`, where a trailing comma is allowed.
+
+Inside the braces the lexer inserts semicolons at line ends as it does in any block, so an
+initialiser written one field per line contains implicit semicolons; they are skipped. It
+returns an `ast.NewExpr`.
+*/
+func parse_new_expr(p *parser) ast.Expr {
+	p.expect(lexer.NEW)
+	instanceType := parse_type(p, default_bp)
+
+	if p.currentTokenKind() == lexer.OPEN_PAREN {
+		arguments, _ := parse_argument_list(p)
+		return ast.NewExpr{
+			Type:      instanceType,
+			Arguments: arguments,
+		}
+	}
+
+	if p.currentTokenKind() != lexer.OPEN_CURLY {
+		p.fail(EXPECTED_TOKEN, "expected '(' or '{' after type in new expression, found %s", describeToken(p.currentToken()))
+	}
+
+	p.advance()
+	fields := []ast.FieldInit{}
+	for p.skipImplicitSemicolons(); p.hasTokens() && p.currentTokenKind() != lexer.CLOSE_CURLY; p.skipImplicitSemicolons() {
+		name := p.expectError(lexer.IDENTIFIER, "field name").Value
+		p.expect(lexer.COLON)
+		fields = append(fields, ast.FieldInit{
+			Name:  name,
+			Value: parse_expr(p, default_bp),
+		})
+
+		p.skipImplicitSemicolons()
+		if p.currentTokenKind() != lexer.CLOSE_CURLY {
+			p.expectError(lexer.COMMA, "',' or '}'")
+		}
+	}
+
+	p.expect(lexer.CLOSE_CURLY)
+	return ast.NewExpr{
+		Type:   instanceType,
+		Fields: fields,
+	}
+}
@@ -0,0 +1,270 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-parser/src/ast"
+)
+
+// exprString Formats an expression compactly, with binary and prefix expressions in
+// parentheses, so tests can spell out the tree they expect.
+func exprString(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case ast.SymbolExpr:
+		return expr.Value
+	case ast.IntegerExpr:
+		return expr.Value.String()
+	case ast.NumberExpr:
+		return fmt.Sprint(expr.Value)
+	case ast.StringExpr:
+		return fmt.Sprintf("%q", expr.Value)
+	case ast.TemplateExpr:
+		parts := []string{expr.Literals[0]}
+		for i, embedded := range expr.Expressions {
+			parts = append(parts, "${"+exprString(embedded)+"}", expr.Literals[i+1])
+		}
+		return fmt.Sprintf("`%s`", strings.Join(parts, ""))
+	case ast.BooleanExpr:
+		return fmt.Sprint(expr.Value)
+	case ast.NullExpr:
+		return "null"
+	case ast.BinaryExpr:
+		return fmt.Sprintf("(%s %s %s)", exprString(expr.Left), expr.Operator.Value, exprString(expr.Right))
+	case ast.PrefixExpr:
+		return fmt.Sprintf("(%s%s)", expr.Operator.Value, exprString(expr.RightExpr))
+	case ast.AssignmentExpr:
+		return fmt.Sprintf("(%s %s %s)", exprString(expr.Assigne), expr.Operator.Value, exprString(expr.Value))
+	case ast.CallExpr:
+		return fmt.Sprintf("%s(%s)", exprString(expr.Callee), exprListString(expr.Arguments))
+	case ast.MemberExpr:
+		return fmt.Sprintf("%s.%s", exprString(expr.Object), expr.Property)
+	case ast.ComputedExpr:
+		return fmt.Sprintf("%s[%s]", exprString(expr.Object), exprString(expr.Index))
+	case ast.ArrayLiteral:
+		return fmt.Sprintf("[%s]", exprListString(expr.Elements))
+	case ast.SpreadExpr:
+		return "..." + exprString(expr.Argument)
+	case ast.NewExpr:
+		if expr.Fields == nil {
+			return fmt.Sprintf("new %s(%s)", typeString(expr.Type), exprListString(expr.Arguments))
+		}
+		fields := make([]string, len(expr.Fields))
+		for i, field := range expr.Fields {
+			fields[i] = fmt.Sprintf("%s: %s", field.Name, exprString(field.Value))
+		}
+		return fmt.Sprintf("new %s{%s}", typeString(expr.Type), strings.Join(fields, ", "))
+	case ast.BadExpr:
+		return "<bad>"
+	default:
+		return fmt.Sprintf("<%T>", expr)
+	}
+}
+
+// typeString Formats a type the way it is written in source.
+func typeString(t ast.Type) string {
+	switch t := t.(type) {
+	case ast.SymbolType:
+		return t.Name
+	case ast.ArrayType:
+		return "[]" + typeString(t.Underlying)
+	case ast.QualifiedType:
+		return typeString(t.Qualifier) + "." + t.Name
+	case ast.GenericType:
+		arguments := make([]string, len(t.Arguments))
+		for i, argument := range t.Arguments {
+			arguments[i] = typeString(argument)
+		}
+		return fmt.Sprintf("%s<%s>", typeString(t.Base), strings.Join(arguments, ", "))
+	default:
+		return fmt.Sprintf("<%T>", t)
+	}
+}
+
+// exprListString Formats a list of expressions separated by commas.
+func exprListString(exprs []ast.Expr) string {
+	parts := make([]string, len(exprs))
+	for i, expr := range exprs {
+		parts[i] = exprString(expr)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseExpr Parses source as a single expression statement and returns its expression.
+func parseExpr(t *testing.T, source string) ast.Expr {
+	t.Helper()
+	block, errs := Parse(source + ";")
+	if len(errs) > 0 {
+		t.Fatalf("Parse(%q) failed: %v", source, errs)
+	}
+	if len(block.Body) != 1 {
+		t.Fatalf("Parse(%q) returned %d statements, want 1", source, len(block.Body))
+	}
+	stmt, ok := block.Body[0].(ast.ExpressionStmt)
+	if !ok {
+		t.Fatalf("Parse(%q) returned %T, want ast.ExpressionStmt", source, block.Body[0])
+	}
+	return stmt.Expression
+}
+
+// TestParseExpressions Checks the shape of the trees built for expressions.
+func TestParseExpressions(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"println(file)", "println(file)"},
+		{"true", "true"},
+		{"false", "false"},
+		{"null", "null"},
+		{"f(true, null)", "f(true, null)"},
+		{"x == null", "(x == null)"},
+		{`"hello ${user.name}!"`, "`hello ${user.name}!`"},
+		{`"${a} + ${b} = ${a + b}"`, "`${a} + ${b} = ${(a + b)}`"},
+		{`"a ${ new Point { x: 1 } } b"`, "`a ${new Point{x: 1}} b`"},
+		{`"${ "in ${x}" }"`, "`${`in ${x}`}`"},
+		{`"\${x}"`, `"${x}"`},
+		{`"a ${x}" + "b"`, "(`a ${x}` + \"b\")"},
+		{"f()", "f()"},
+		{"f(a, b,)", "f(a, b)"},
+		{"f(\n  a,\n  b,\n)", "f(a, b)"},
+		{"f(\n  a,\n  b\n)", "f(a, b)"},
+		{"(\n  a + b\n) * c", "((a + b) * c)"},
+		{"f(g(x), 1 + 2)", "f(g(x), (1 + 2))"},
+		{"getFn()(x)", "getFn()(x)"},
+		{"(f)(x)", "f(x)"},
+		{"a + f(b) * 2", "(a + (f(b) * 2))"},
+		{"-f(x)", "(-f(x))"},
+		{"x = f(1)", "(x = f(1))"},
+		{"this.directoryPath", "this.directoryPath"},
+		{"fs.stat(file).creationTime", "fs.stat(file).creationTime"},
+		{"reader.mount(directory)", "reader.mount(directory)"},
+		{"array[index]", "array[index]"},
+		{"a[i][j](x).y", "a[i][j](x).y"},
+		{"a[f(1) + 2]", "a[(f(1) + 2)]"},
+		{"a[\n  i\n]", "a[i]"},
+		{"a.b + c.d * e[1]", "(a.b + (c.d * e[1]))"},
+		{"-a.b", "(-a.b)"},
+		{"x.in(list)", "x.in(list)"},
+		{"a.b = c[0]", "(a.b = c[0])"},
+		{"[]", "[]"},
+		{"[1, 2, 3,]", "[1, 2, 3]"},
+		{"[\n  \"a\",\n  \"b\"\n]", "[\"a\", \"b\"]"},
+		{"[...a, b]", "[...a, b]"},
+		{"[...f(x), ...[1, 2]]", "[...f(x), ...[1, 2]]"},
+		{"[[1], [2, 3]][0]", "[[1], [2, 3]][0]"},
+		{"a..b", "(a .. b)"},
+		{"new DirectoryReader()", "new DirectoryReader()"},
+		{"new fs.Reader(path, 1,)", "new fs.Reader(path, 1)"},
+		{"new Map<string, []number>()", "new Map<string, []number>()"},
+		{"new Map<a\n, b>()", "new Map<a, b>()"},
+		{"new Map<\n  string,\n  number\n>()", "new Map<string, number>()"},
+		{"[1,\n2\n,3]", "[1, 2, 3]"},
+		{"new a.b.C<T,>().run()", "new a.b.C<T>().run()"},
+		{"new Point { x: 1, y: f(2) }", "new Point{x: 1, y: f(2)}"},
+		{"new Point {\n  x: 1,\n  y: 2\n}", "new Point{x: 1, y: 2}"},
+		{"new Point {\n  x: 1,\n  y: 2,\n}", "new Point{x: 1, y: 2}"},
+		{"new Point {\n}", "new Point{}"},
+		{"new Pair<K, V> { first: new K(), second: [] }", "new Pair<K, V>{first: new K(), second: []}"},
+	}
+
+	for _, test := range tests {
+		if got := exprString(parseExpr(t, test.source)); got != test.want {
+			t.Errorf("%q parsed as %s, want %s", test.source, got, test.want)
+		}
+	}
+}
+
+// TestCallParens Checks that a call records the span of its parentheses.
+func TestCallParens(t *testing.T) {
+	call, ok := parseExpr(t, "print(a, b)").(ast.CallExpr)
+	if !ok {
+		t.Fatal("print(a, b) did not parse as a call")
+	}
+	if call.Parens.String() != "1:6" || call.Parens.Len() != len("(a, b)") {
+		t.Errorf("parentheses of print(a, b) span %s with length %d, want 1:6 with length 6", call.Parens, call.Parens.Len())
+	}
+}
+
+// TestParseNewWithTypes Checks that new expressions and declared types share the type syntax.
+func TestParseNewWithTypes(t *testing.T) {
+	block, errs := Parse("const reader = new DirectoryReader()\nlet m: Map<string, fs.FileInfo> = new Map<string, fs.FileInfo>()\n")
+	if len(errs) > 0 {
+		t.Fatalf("Parse failed: %v", errs)
+	}
+	if len(block.Body) != 2 {
+		t.Fatalf("Parse returned %d statements, want 2", len(block.Body))
+	}
+
+	decl := block.Body[1].(ast.VarDeclStmt)
+	if got := typeString(decl.ExplicitType); got != "Map<string, fs.FileInfo>" {
+		t.Errorf("declared type parsed as %s", got)
+	}
+	if got := exprString(decl.AssignedValue); got != "new Map<string, fs.FileInfo>()" {
+		t.Errorf("value parsed as %s", got)
+	}
+}
+
+// TestParseTemplate Checks the literals and expressions of a parsed template string.
+func TestParseTemplate(t *testing.T) {
+	template, ok := parseExpr(t, `"hello ${user.name}!"`).(ast.TemplateExpr)
+	if !ok {
+		t.Fatalf("template parsed as %T, want ast.TemplateExpr", template)
+	}
+	if len(template.Literals) != 2 || template.Literals[0] != "hello " || template.Literals[1] != "!" {
+		t.Errorf("template literals are %q, want [\"hello \" \"!\"]", template.Literals)
+	}
+	if len(template.Expressions) != 1 {
+		t.Fatalf("template has %d expressions, want 1", len(template.Expressions))
+	}
+	if member, ok := template.Expressions[0].(ast.MemberExpr); !ok || exprString(member) != "user.name" {
+		t.Errorf("template expression is %#v, want the member expression user.name", template.Expressions[0])
+	}
+}
+
+// TestParseKeywordLiterals Checks that null, true and false parse as literals in declarations,
+// where null used to be an ordinary identifier.
+func TestParseKeywordLiterals(t *testing.T) {
+	block, errs := Parse("let x = null;\nlet t = true\nconst f: bool = false\n")
+	if len(errs) > 0 {
+		t.Fatalf("Parse failed: %v", errs)
+	}
+
+	want := []ast.Expr{ast.NullExpr{}, ast.BooleanExpr{Value: true}, ast.BooleanExpr{Value: false}}
+	for i, value := range want {
+		decl, ok := block.Body[i].(ast.VarDeclStmt)
+		if !ok || decl.AssignedValue != value {
+			t.Errorf("statement %d is %#v, want a declaration of %s", i, block.Body[i], exprString(value))
+		}
+	}
+}
+
+// TestParseIntegerLiterals Checks that integer literals of any base and size keep their exact value.
+func TestParseIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"0", "0"},
+		{"1_000_000", "1000000"},
+		{"0x_FF", "255"},
+		{"0XdeadBEEF", "3735928559"},
+		{"0o777", "511"},
+		{"0b1010_0101", "165"},
+		{"18446744073709551616", "18446744073709551616"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"0xFFFFFFFFFFFFFFFFFFFFFFFF", "79228162514264337593543950335"},
+	}
+
+	for _, test := range tests {
+		integer, ok := parseExpr(t, test.source).(ast.IntegerExpr)
+		if !ok {
+			t.Errorf("%q did not parse as an integer", test.source)
+			continue
+		}
+		if got := integer.Value.String(); got != test.want {
+			t.Errorf("%q parsed as %s, want %s", test.source, got, test.want)
+		}
+	}
+}
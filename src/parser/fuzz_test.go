@@ -0,0 +1,29 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// FuzzParse Checks that no input makes the parser panic: every failure must come back as an error.
+func FuzzParse(f *testing.F) {
+	paths, err := filepath.Glob("../../examples/*.lang")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		example, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(example))
+	}
+	f.Add("let a = " + strings.Repeat("(", 5000) + "1;")
+	f.Add("let s = \"${\"${\"${x}\"}\"}\";")
+
+	f.Fuzz(func(t *testing.T, source string) {
+		Parse(source)
+	})
+}
@@ -31,23 +31,61 @@ type nud_lookup map[lexer.TokenKind]nud_handler
 type led_lookup map[lexer.TokenKind]led_handler
 type bp_lookup map[lexer.TokenKind]binding_power
 
-var bp_lu = bp_lookup{}
-var nud_lu = nud_lookup{}
-var led_lu = led_lookup{}
-var stmt_lu = stmt_lookup{}
-
-func led(kind lexer.TokenKind, bp binding_power, led_fn led_handler) {
-	bp_lu[kind] = bp
-	led_lu[kind] = led_fn
+/*
+This class definition defines a struct called `grammar` in Go, which holds the lookup tables that drive the Pratt parser. Here's a succinct explanation of what each field does:
+
+* `bp_lu`, `nud_lu`, `led_lu`: the binding power and the prefix and infix handlers of each token kind in expressions.
+* `stmt_lu`: the handler of each token kind that starts a statement.
+* `type_bp_lu`, `type_nud_lu`, `type_led_lu`: the same tables for types, see `parse_type`.
+
+A grammar is filled in once by `newGrammar` and never changed afterwards, so a single value is
+shared by every parser and parsers may run on several goroutines at once.
+*/
+type grammar struct {
+	bp_lu   bp_lookup
+	nud_lu  nud_lookup
+	led_lu  led_lookup
+	stmt_lu stmt_lookup
+
+	type_bp_lu  type_bp_lookup
+	type_nud_lu type_nud_lookup
+	type_led_lu type_led_lookup
 }
 
-func nud(kind lexer.TokenKind, nud_fn nud_handler) {
-	nud_lu[kind] = nud_fn
+// defaultGrammar Is the grammar of the language, built once and read by every parser.
+var defaultGrammar = newGrammar()
+
+/*
+This Go function, `newGrammar`, builds a grammar by filling in empty lookup tables with
+`createTokenLookups` and `createTokenTypeLookups`. It is the only place the tables are written.
+*/
+func newGrammar() *grammar {
+	g := &grammar{
+		bp_lu:       bp_lookup{},
+		nud_lu:      nud_lookup{},
+		led_lu:      led_lookup{},
+		stmt_lu:     stmt_lookup{},
+		type_bp_lu:  type_bp_lookup{},
+		type_nud_lu: type_nud_lookup{},
+		type_led_lu: type_led_lookup{},
+	}
+	g.createTokenLookups()
+	g.createTokenTypeLookups()
+	return g
 }
 
-func stmt(kind lexer.TokenKind, stmt_fn stmt_handler) {
-	bp_lu[kind] = default_bp
-	stmt_lu[kind] = stmt_fn
+func (g *grammar) led(kind lexer.TokenKind, bp binding_power, led_fn led_handler) {
+	g.bp_lu[kind] = bp
+	g.led_lu[kind] = led_fn
+}
+
+func (g *grammar) nud(kind lexer.TokenKind, nud_fn nud_handler) {
+	g.nud_lu[kind] = nud_fn
+}
+
+func (g *grammar) stmt(kind lexer.TokenKind, stmt_fn stmt_handler) {
+	g.bp_lu[kind] = default_bp
+	g.stmt_lu[kind] = stmt_fn
 }
 
 // array[index] // computed expression // LED
@@ -55,58 +93,73 @@ func stmt(kind lexer.TokenKind, stmt_fn stmt_handler) {
 // let foo; []number; // TYPE_NUD
 
 /*
-This Go function, `createTokenLookups`, sets up token lookups for a parser. It defines the binding power and parsing functions for various token kinds, including:
+This Go function, `createTokenLookups`, sets up the expression and statement lookups of a grammar. It defines the binding power and parsing functions for various token kinds, including:
 
 * Assignment operators (`=`)
 * Logical operators (`&&`, `||`, `..`)
 * Relational operators (`<`, `>`, `==`, `!=`)
 * Additive operators (`+`, `-`)
 * Multiplicative operators (`*`, `/`, `%`)
-* Literals and symbols (`number`, `string`, `identifier`, `(`, `-`)
+* Function calls (`(`), member access (`.`) and indexing (`[`)
+* Literals and symbols (`int`, `float`, `string`, template strings, `identifier`, `(`, `-`, array literals `[`, `new`)
 * Statements (`const`, `let`)
 
 The `led` function sets up left-denotation (infix) operators, while the `nud` function sets up null-denotation (prefix) operators. The `stmt` function sets up statement handlers.
 
 In essence, this function tells the parser how to handle different tokens and what parsing functions to call when encountering them.
 */
-func createTokenLookups() {
-	led(lexer.ASSIGNMENT, assignment, parse_assignment_expr)
-	led(lexer.PLUS_EQUALS, assignment, parse_assignment_expr)
-	led(lexer.MINUS_EQUALS, assignment, parse_assignment_expr)
+func (g *grammar) createTokenLookups() {
+	g.led(lexer.ASSIGNMENT, assignment, parse_assignment_expr)
+	g.led(lexer.PLUS_EQUALS, assignment, parse_assignment_expr)
+	g.led(lexer.MINUS_EQUALS, assignment, parse_assignment_expr)
 
 	// Logical
-	led(lexer.AND, logical, parse_binary_expr)
-	led(lexer.OR, logical, parse_binary_expr)
-	led(lexer.DOT_DOT, logical, parse_binary_expr) // 10..math.random()
+	g.led(lexer.AND, logical, parse_binary_expr)
+	g.led(lexer.OR, logical, parse_binary_expr)
+	g.led(lexer.DOT_DOT, logical, parse_binary_expr) // 10..math.random()
 
 	// Relational
-	led(lexer.LESS_EQUALS, relational, parse_binary_expr)
-	led(lexer.LESS, relational, parse_binary_expr)
-	led(lexer.GREATER_EQUALS, relational, parse_binary_expr)
-	led(lexer.GREATER, relational, parse_binary_expr)
-	led(lexer.NOT_EQUALS, relational, parse_binary_expr)
-	led(lexer.EQUALS, relational, parse_binary_expr)
+	g.led(lexer.LESS_EQUALS, relational, parse_binary_expr)
+	g.led(lexer.LESS, relational, parse_binary_expr)
+	g.led(lexer.GREATER_EQUALS, relational, parse_binary_expr)
+	g.led(lexer.GREATER, relational, parse_binary_expr)
+	g.led(lexer.NOT_EQUALS, relational, parse_binary_expr)
+	g.led(lexer.EQUALS, relational, parse_binary_expr)
 
 	// Additive
-	led(lexer.PLUS, additive, parse_binary_expr)
-	led(lexer.DASH, additive, parse_binary_expr)
+	g.led(lexer.PLUS, additive, parse_binary_expr)
+	g.led(lexer.DASH, additive, parse_binary_expr)
 
 	// Multiplicative
-	led(lexer.STAR, multiplicative, parse_binary_expr)
-	led(lexer.SLASH, multiplicative, parse_binary_expr)
-	led(lexer.PERCENT, multiplicative, parse_binary_expr)
+	g.led(lexer.STAR, multiplicative, parse_binary_expr)
+	g.led(lexer.SLASH, multiplicative, parse_binary_expr)
+	g.led(lexer.PERCENT, multiplicative, parse_binary_expr)
+
+	// Call & Member
+	g.led(lexer.OPEN_PAREN, call, parse_call_expr)
+	g.led(lexer.DOT, member, parse_member_expr)
+	g.led(lexer.OPEN_BRACKET, member, parse_computed_expr)
 
 	// Literals & Symbols
-	nud(lexer.NUMBER, parse_primary_expr)
+	g.nud(lexer.INT, parse_primary_expr)
+	g.nud(lexer.FLOAT, parse_primary_expr)
+
+	g.nud(lexer.STRING, parse_primary_expr)
+	g.nud(lexer.TEMPLATE_HEAD, parse_template_expr)
 
-	nud(lexer.STRING, parse_primary_expr)
+	g.nud(lexer.TRUE, parse_primary_expr)
+	g.nud(lexer.FALSE, parse_primary_expr)
+	g.nud(lexer.NULL, parse_primary_expr)
 
-	nud(lexer.IDENTIFIER, parse_primary_expr)
-	nud(lexer.OPEN_PAREN, parse_grouping_expr)
-	nud(lexer.DASH, parse_prefix_expr)
+	g.nud(lexer.IDENTIFIER, parse_primary_expr)
+	g.nud(lexer.OPEN_PAREN, parse_grouping_expr)
+	g.nud(lexer.OPEN_BRACKET, parse_array_literal_expr)
+	g.nud(lexer.NEW, parse_new_expr)
+	g.nud(lexer.DASH, parse_prefix_expr)
 
 	// statements
-	stmt(lexer.CONST, parse_var_decl_stmt)
-	stmt(lexer.LET, parse_var_decl_stmt)
+	g.stmt(lexer.CONST, parse_var_decl_stmt)
+	g.stmt(lexer.LET, parse_var_decl_stmt)
+	g.stmt(lexer.IMPORT, parse_import_stmt)
 
 }
@@ -1,7 +1,12 @@
 package parser
 
 import (
-	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
 	"github.com/go-parser/src/ast"
 	"github.com/go-parser/src/lexer"
 )
@@ -9,65 +14,143 @@ import (
 /*
 This class definition defines a struct called `parser` in Go. Here's a succinct explanation of what each field does:
 
-* `tokens []lexer.Token`: This field stores a list of tokens found in the source code being parsed.
-* `pos int`: This field stores the current position in the token list.
+* `lex *lexer.Lexer`: This field stores the lexer the parser pulls its tokens from, one at a time.
+* `grammar *grammar`: This field stores the lookup tables the parser dispatches on. They are shared and never written, see `grammar`.
+* `current lexer.Token`: This field stores the token currently being processed.
+* `doc string`: This field stores the doc comment written directly above the current token.
+* `depth int`: This field stores how deeply the expression or type being parsed is nested, see `nest`.
+* `curlies int`: This field stores how many `{` the parser has advanced past and not yet closed, see `synchronise`.
+* `errors ErrorList`: This field stores the errors reported so far, see `report`.
+* `reported map[int]struct{}`: This field stores the offsets errors have been reported at, so each position is reported once.
+* `lexErrors int`: This field stores how many of the lexer's errors have already been reported.
 
-Note that this struct does not have any methods, it only has fields to store the tokens and the current position.
+Tokens are pulled from the lexer as the parser advances, so only the current token is held in memory.
 */
 type parser struct {
-	tokens []lexer.Token
-	pos    int
+	lex     *lexer.Lexer
+	grammar *grammar
+	current lexer.Token
+	doc     string
+	depth   int
+	curlies int
+
+	errors    ErrorList
+	reported  map[int]struct{}
+	lexErrors int
 }
 
 /*
 This is a Go function named `createParser` that creates and returns a new instance of
-the `parser` struct. The function takes a slice of `lexer.Token` objects as input and
-initializes the `parser` struct with this token list and a position of 0. Before creating
-the `parser` instance, it calls two other functions: `createTokenLookups`
-and `createTokenTypeLookups`, likely to set up some internal state or mappings used by the parser.
-*/
-func createParser(tokens []lexer.Token) *parser {
-	createTokenLookups()
-	createTokenTypeLookups()
-	return &parser{
-		tokens: tokens, pos: 0,
-	}
+the `parser` struct. The function takes the `lexer.Lexer` the tokens are read from and
+initializes the `parser` struct with its first token. Every parser reads the shared,
+immutable `defaultGrammar`, so creating one writes no package state and parsers can be
+created and run from several goroutines at once.
+*/
+func createParser(lex *lexer.Lexer) *parser {
+	p := &parser{lex: lex, grammar: defaultGrammar, reported: map[int]struct{}{}}
+	p.next()
+	return p
+}
+
+/*
+This Go function, `Parse`, takes a source string as input and returns a parsed abstract syntax tree (AST) as an `ast.BlockStmt`.
+It is a convenience wrapper around `ParseReader` for source code that is already in memory.
+*/
+func Parse(source string) (ast.BlockStmt, ErrorList) {
+	return ParseReader("", strings.NewReader(source))
 }
 
 /*
-This Go function, `Parse`, takes a source string as input and returns a parsed abstract syntax tree (AST) as an `ast.BlockStmt`. Here's a succinct explanation of what the function does:
+This Go function, `ParseReader`, reads source code from `r` and returns a parsed abstract syntax tree (AST) as an `ast.BlockStmt`. Here's a succinct explanation of what the function does:
 
-1. It tokenizes the input source string into a list of tokens using the `lexer.Tokenize` function.
-2. It creates a new parser instance with the tokenized input using the `createParser` function.
+1. It creates a `lexer.Lexer` that reads tokens from `r` on demand.
+2. It creates a new parser instance pulling from that lexer using the `createParser` function.
 3. It initializes an empty list to store parsed statements.
 4. It enters a loop that continues as long as there are tokens left to parse.
 5. Inside the loop, it parses a single statement using the `parse_stmt` function and appends it to the list of parsed statements.
 6. Once all tokens have been parsed, it returns a new `ast.BlockStmt` instance with the list of parsed statements as its body.
 
-In essence, this function is the entry point for parsing source code into an abstract syntax tree.
+The file name is recorded in the span of every token. Every problem is returned in the `ErrorList`
+with its span and `ErrorCode`, sorted by position; lexing errors are reported with the code
+`LEX_ERROR`. After an error the parser resynchronises at the next statement, leaving `ast.BadStmt`
+and `ast.BadExpr` nodes where source could not be parsed, so the rest of the tree is still usable.
+No input can make the parser panic: every failure, including input nested too deeply to parse,
+comes back in the list, so callers never need to `recover`.
 */
-func Parse(source string) ast.BlockStmt {
-	tokens := lexer.Tokenize(source)
-	p := createParser(tokens)
+func ParseReader(filename string, r io.Reader) (ast.BlockStmt, ErrorList) {
+	p := createParser(lexer.NewLexer(filename, r))
 	body := make([]ast.Stmt, 0)
 	// while we have tokens, continue to parse
 	for p.hasTokens() {
 		body = append(body, parse_stmt(p))
 	}
 
+	// lexing errors are found a token ahead of the parser, so they can be reported out of order
+	sort.SliceStable(p.errors, func(i, j int) bool {
+		return p.errors[i].Span.Start.Offset < p.errors[j].Span.Start.Offset
+	})
+
 	return ast.BlockStmt{
 		Body: body,
+	}, p.errors
+}
+
+/*
+This Go function, `ParseFile`, parses a file from an `ast.FileSet`. It behaves like `ParseReader`
+and records the file's name in every token span. Token offsets are offsets into `file.Contents`,
+so `file.Position` and the set's `Position` can turn them back into lines and columns.
+*/
+func ParseFile(file *ast.SourceFile) (ast.BlockStmt, ErrorList) {
+	return ParseReader(file.Name, strings.NewReader(file.Contents))
+}
+
+/*
+This Go function, `ParseFiles`, parses several files in parallel and returns their trees and
+error lists in the same order as `files`. At most `workers` files are parsed at once; if
+`workers` is zero or negative, one worker is used per CPU. Each file is parsed exactly as
+`ParseFile` would parse it.
+*/
+func ParseFiles(files []*ast.SourceFile, workers int) ([]ast.BlockStmt, []ErrorList) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(files) {
+		workers = len(files)
+	}
+
+	blocks := make([]ast.BlockStmt, len(files))
+	errs := make([]ErrorList, len(files))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			// each worker writes only the results of the files it was handed
+			for j := range jobs {
+				blocks[j], errs[j] = ParseFile(files[j])
+			}
+		}()
+	}
+
+	for i := range files {
+		jobs <- i
 	}
+	close(jobs)
+	wg.Wait()
+
+	return blocks, errs
 }
 
 // HELPER FUNCTIONS
 
 /*
 This Go function, `currentToken`, returns the current token being processed
-by the parser. It does this by accessing the `tokens` slice at the current position `p.pos`.
+by the parser.
 */
 func (p *parser) currentToken() lexer.Token {
-	return p.tokens[p.pos]
+	return p.current
 }
 
 /*
@@ -79,67 +162,139 @@ func (p *parser) currentTokenKind() lexer.TokenKind {
 	return p.currentToken().Kind
 }
 
+/*
+This Go function, `docComment`, returns the doc comment written directly above the
+current token, or an empty string if there is none.
+*/
+func (p *parser) docComment() string {
+	return p.doc
+}
+
+/*
+This Go function, `next`, pulls the next token from the lexer into `current`.
+
+Doc comment tokens are not handed to the grammar. Consecutive `///` lines are joined
+with newlines and remembered against the token that follows them, so declarations can pick
+up their documentation with `docComment`. Lexing errors are reported as soon as they are found,
+and the ILLEGAL tokens the lexer emits for them are handed on like any other token.
+*/
+func (p *parser) next() {
+	lines := []string{}
+	token := p.lex.Next()
+	for token.Kind == lexer.DOC_COMMENT {
+		lines = append(lines, token.Value)
+		token = p.lex.Next()
+	}
+
+	p.reportLexErrors()
+
+	p.current = token
+	p.doc = strings.Join(lines, "\n")
+}
+
 /*
 This Go function, `advance`, advances the parser's position to the next
 token in the token list and returns the current token that was just advanced past.
+It also keeps count of the braces opened and not yet closed, for `synchronise`.
 */
 func (p *parser) advance() lexer.Token {
 
 	tk := p.currentToken()
-	p.pos++
+	switch tk.Kind {
+	case lexer.OPEN_CURLY:
+		p.curlies++
+	case lexer.CLOSE_CURLY:
+		if p.curlies > 0 {
+			p.curlies--
+		}
+	}
+
+	p.next()
 	return tk
 }
 
 /*
 This Go function, `hasTokens`, checks if the parser has more tokens to
-process. It returns `true` if the parser's current position (`p.pos`) is
-within the bounds of the token list (`p.tokens`) and the current token is
-not an end-of-file (`EOF`) token.
+process. It returns `true` if the current token is not an end-of-file (`EOF`) token.
 */
 func (p *parser) hasTokens() bool {
-	return p.pos < len(p.tokens) && p.currentTokenKind() != lexer.EOF
+	return p.currentTokenKind() != lexer.EOF
 }
 
 /*
 This code snippet is a method called `expectError` defined on the `parser` struct in Go.
-It takes two parameters: `expectedKind` of type `lexer.TokenKind` and `err` of type `any`.
+It takes two parameters: `expectedKind` of type `lexer.TokenKind` and `expected` of type `string`.
 
 The purpose of this method is to check if the current token in the parser matches the
-expected token kind. If the token kind does not match, it checks if an error message is
-provided (`err != nil`). If an error message is provided, it formats and panics with
-the error message. If no error message is provided, it panics with a default error message.
+expected token kind. If the token kind does not match, it fails with the message
+"expected <expected>, found <current token>", where `expected` describes what belongs at this
+point of the source, such as "variable name". If `expected` is empty, the spelling or name of
+`expectedKind` is used instead.
 
 The method returns the token that was advanced past in the parser.
 */
-func (p *parser) expectError(expectedKind lexer.TokenKind, err any) lexer.Token {
-	token := p.currentToken()
-	kind := token.Kind
-
-	if kind != expectedKind {
-		if err == nil {
-			err = fmt.Sprintf("Expected %s, but received %s instead\n", lexer.TokenKindString(expectedKind), lexer.TokenKindString(kind))
-
+func (p *parser) expectError(expectedKind lexer.TokenKind, expected string) lexer.Token {
+	if p.currentTokenKind() != expectedKind {
+		if expected == "" {
+			expected = describeKind(expectedKind)
 		}
-		panic(err)
+		p.fail(EXPECTED_TOKEN, "expected %s, found %s", expected, describeToken(p.currentToken()))
 	}
 
 	return p.advance()
+}
 
+/*
+This Go function, `currentIsKeyword`, reports whether the current token is the keyword `kind`.
+Contextual keywords such as `from` reach the parser as identifiers, so an identifier spelled
+like the keyword counts too. Grammar positions that need a contextual keyword use this
+instead of comparing token kinds, and everywhere else the word stays an ordinary name.
+*/
+func (p *parser) currentIsKeyword(kind lexer.TokenKind) bool {
+	token := p.currentToken()
+	return token.Kind == kind || (token.Kind == lexer.IDENTIFIER && token.Value == kind.Spelling())
+}
+
+/*
+This Go function, `expectKeyword`, is `expect` for keywords that may be contextual. It fails
+unless `currentIsKeyword` holds, and returns the token advanced past with its kind set to
+`kind`, so callers see the keyword whether or not the lexer reserved it.
+*/
+func (p *parser) expectKeyword(kind lexer.TokenKind) lexer.Token {
+	if !p.currentIsKeyword(kind) {
+		p.fail(EXPECTED_TOKEN, "expected %s, found %s", describeKind(kind), describeToken(p.currentToken()))
+	}
+
+	token := p.advance()
+	token.Kind = kind
+	return token
+}
+
+/*
+This Go function, `skipImplicitSemicolons`, advances past semicolons the lexer inserted at line
+ends, for constructs that are not statement lists, such as argument lists, array literals and
+keyed initialisers, so they can span several lines.
+Semicolons written in the source are left alone.
+*/
+func (p *parser) skipImplicitSemicolons() {
+	for p.currentTokenKind() == lexer.SEMI_COLON && p.currentToken().Is(lexer.IMPLICIT) {
+		p.advance()
+	}
 }
 
 /*
 This code snippet defines a method `expect` on the `parser` struct in Go. It takes
 a `lexer.TokenKind` parameter `expectedKind` and returns a `lexer.Token`.
 
-The method calls the `expectError` method with `expectedKind` and `nil` as parameters.
+The method calls the `expectError` method with `expectedKind` and an empty description.
 
 In essence, this method checks if the current token in the parser matches the `expectedKind`.
-If it does not match, it panics with a default error message. If it matches, it returns
+If it does not match, it fails with a default error message. If it matches, it returns
 the token that was advanced past in the parser.
 
 This method is a convenience wrapper around `expectError` that provides a default
 error message when the token kind does not match.
 */
 func (p *parser) expect(expectedKind lexer.TokenKind) lexer.Token {
-	return p.expectError(expectedKind, nil)
+	return p.expectError(expectedKind, "")
 }
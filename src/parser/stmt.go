@@ -15,9 +15,13 @@ Here's a step-by-step explanation:
 3. If no handler function exists, it falls back to parsing an expression statement using the `parse_expression_stmt` function and returns the result.
 
 In essence, this function dispatches the parsing of a statement to a specific handler function based on the current token kind, or defaults to parsing an expression statement if no specific handler is found.
+
+If the statement has a syntax error, `recoverStmt` records it, skips to the next statement and returns an `ast.BadStmt` instead.
 */
-func parse_stmt(p *parser) ast.Stmt {
-	stmt_fn, exists := stmt_lu[p.currentTokenKind()]
+func parse_stmt(p *parser) (stmt ast.Stmt) {
+	defer p.recoverStmt(p.currentToken(), p.curlies, &stmt)
+
+	stmt_fn, exists := p.grammar.stmt_lu[p.currentTokenKind()]
 
 	if exists {
 		return stmt_fn(p)
@@ -53,9 +57,10 @@ values, and returns an `ast.VarDeclStmt` object containing the parsed informatio
 */
 func parse_var_decl_stmt(p *parser) ast.Stmt {
 	var explicitType ast.Type
+	doc := p.docComment()
 	startToken := p.advance().Kind
 	isConstant := startToken == lexer.CONST
-	varName := p.expectError(lexer.IDENTIFIER, "Inside variable declaration expected to find variable name").Value
+	nameToken := p.expectError(lexer.IDENTIFIER, "variable name")
 	// Explicit type could be present
 	if p.currentTokenKind() == lexer.COLON {
 		p.expect(lexer.COLON)
@@ -66,18 +71,42 @@ func parse_var_decl_stmt(p *parser) ast.Stmt {
 		p.expect(lexer.ASSIGNMENT)
 		assignmentValue = parse_expr(p, assignment)
 	} else if explicitType == nil {
-		panic("Missing either right hand side in var declaration or explicit type.")
+		p.failAt(nameToken.Span, MISSING_VALUE, "variable '%s' needs a type or a value", nameToken.Value)
 	}
 
 	p.expect(lexer.SEMI_COLON)
 	if isConstant && assignmentValue == nil {
-		panic("Cannot define constant without providing value")
+		p.failAt(nameToken.Span, MISSING_VALUE, "constant '%s' needs a value", nameToken.Value)
 	}
 
 	return ast.VarDeclStmt{
 		ExplicitType:  explicitType,
 		IsConstant:    isConstant,
-		VariableName:  varName,
+		VariableName:  nameToken.Value,
 		AssignedValue: assignmentValue,
+		Doc:           doc,
+	}
+}
+
+/*
+This function, `parse_import_stmt`, parses an import statement such as `import fs;` or
+`import path from "std/path";`. `from` is a contextual keyword: it is only treated as a
+keyword directly after the imported name, so it remains usable as a variable name elsewhere.
+Without a `from` clause the module is looked up by the imported name.
+*/
+func parse_import_stmt(p *parser) ast.Stmt {
+	p.expect(lexer.IMPORT)
+	name := p.expectError(lexer.IDENTIFIER, "module name").Value
+	from := name
+
+	if p.currentIsKeyword(lexer.FROM) {
+		p.expectKeyword(lexer.FROM)
+		from = p.expectError(lexer.STRING, "module path string").Value
+	}
+
+	p.expect(lexer.SEMI_COLON)
+	return ast.ImportStmt{
+		Name: name,
+		From: from,
 	}
 }
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/go-parser/src/ast"
+)
+
+// TestParseDocComments Checks that the /// lines directly above a declaration are joined into its
+// Doc, and that other comments and doc comments above other statements are not.
+func TestParseDocComments(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []string
+	}{
+		{"/// The answer.\nlet a = 42", []string{"The answer."}},
+		{"/// One\n///   two\n///\nconst a = 1", []string{"One\n  two\n"}},
+		{"let a = 1\n/// For b.\nconst b = 2", []string{"", "For b."}},
+		{"// plain\n/* block */\nlet a = 1", []string{""}},
+		{"//// not doc\nlet a = 1", []string{""}},
+		{"/// stray\nf()\nlet a = 1", []string{"", ""}},
+	}
+
+	for _, test := range tests {
+		block, errs := Parse(test.source)
+		if len(errs) > 0 {
+			t.Errorf("Parse(%q) failed: %v", test.source, errs)
+			continue
+		}
+		if len(block.Body) != len(test.want) {
+			t.Errorf("Parse(%q) returned %d statements, want %d", test.source, len(block.Body), len(test.want))
+			continue
+		}
+		for i, want := range test.want {
+			decl, ok := block.Body[i].(ast.VarDeclStmt)
+			if !ok {
+				continue
+			}
+			if decl.Doc != want {
+				t.Errorf("Parse(%q): %s has doc %q, want %q", test.source, decl.VariableName, decl.Doc, want)
+			}
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"github.com/go-parser/src/ast"
 	"github.com/go-parser/src/lexer"
 )
@@ -45,10 +44,6 @@ type type_nud_lookup map[lexer.TokenKind]type_nud_handler
 type type_led_lookup map[lexer.TokenKind]type_led_handler
 type type_bp_lookup map[lexer.TokenKind]binding_power
 
-var type_bp_lu = bp_lookup{}
-var type_nud_lu = type_nud_lookup{}
-var type_led_lu = type_led_lookup{}
-
 /*
 This function, `type_led`, sets up a left-denotation (infix) operator for a given token kind in the parser. It takes three parameters:
 
@@ -58,9 +53,9 @@ This function, `type_led`, sets up a left-denotation (infix) operator for a give
 
 The function updates two lookup tables: `type_bp_lu` and `type_led_lu`, which store the binding power and the handling function for the token, respectively.
 */
-func type_led(kind lexer.TokenKind, bp binding_power, led_fn type_led_handler) {
-	type_bp_lu[kind] = bp
-	type_led_lu[kind] = led_fn
+func (g *grammar) type_led(kind lexer.TokenKind, bp binding_power, led_fn type_led_handler) {
+	g.type_bp_lu[kind] = bp
+	g.type_led_lu[kind] = led_fn
 }
 
 /*
@@ -71,19 +66,24 @@ This function, `type_nud`, sets up a null-denotation (prefix) operator for a giv
 
 The function updates the `type_nud_lu` lookup table, which stores the handling function for the token.
 */
-func type_nud(kind lexer.TokenKind, nud_fn type_nud_handler) {
-	type_nud_lu[kind] = nud_fn
+func (g *grammar) type_nud(kind lexer.TokenKind, nud_fn type_nud_handler) {
+	g.type_nud_lu[kind] = nud_fn
 }
 
 /*
-This function, `createTokenTypeLookups`, sets up token type lookups for the parser. It defines two null-denotation (prefix) operators:
+This function, `createTokenTypeLookups`, sets up the type lookups of a grammar. It defines two null-denotation (prefix) operators:
 
 *   When the parser encounters an `IDENTIFIER` token, it will call the `parse_symbol_type` function to parse the identifier as a symbol type.
 *   When the parser encounters an `OPEN_BRACKET` token, it will call the `parse_array_type` function to parse the array type.
+
+It also defines two left-denotation (infix) operators, `.` for qualified types such as `fs.FileInfo` and `<` for generic types such as `Map<string, number>`.
 */
-func createTokenTypeLookups() {
-	type_nud(lexer.IDENTIFIER, parse_symbol_type)
-	type_nud(lexer.OPEN_BRACKET, parse_array_type)
+func (g *grammar) createTokenTypeLookups() {
+	g.type_nud(lexer.IDENTIFIER, parse_symbol_type)
+	g.type_nud(lexer.OPEN_BRACKET, parse_array_type)
+
+	g.type_led(lexer.DOT, member, parse_qualified_type)
+	g.type_led(lexer.LESS, member, parse_generic_type)
 }
 
 /*
@@ -124,25 +124,27 @@ This Go function, `parse_type`, recursively parses a type expression in the abst
 The function returns the fully parsed type expression.
 */
 func parse_type(p *parser, bp binding_power) ast.Type {
+	defer p.nest()()
+
 	// First parse the NUD
 	tokenKind := p.currentTokenKind()
-	nud_fn, exists := type_nud_lu[tokenKind]
+	nud_fn, exists := p.grammar.type_nud_lu[tokenKind]
 
 	if !exists {
-		panic(fmt.Sprintf("TYPE_NUD handler expected for token %s\n", lexer.TokenKindString(tokenKind)))
+		p.fail(EXPECTED_TYPE, "expected type, found %s", describeToken(p.currentToken()))
 	}
 
 	left := nud_fn(p)
 
-	for type_bp_lu[p.currentTokenKind()] > bp {
+	for p.grammar.type_bp_lu[p.currentTokenKind()] > bp {
 		tokenKind = p.currentTokenKind()
-		led_fn, exists := type_led_lu[tokenKind]
+		led_fn, exists := p.grammar.type_led_lu[tokenKind]
 
 		if !exists {
-			panic(fmt.Sprintf("TYPE_LED handler expected for token %s\n", lexer.TokenKindString(tokenKind)))
+			p.fail(UNEXPECTED_TOKEN, "unexpected %s in type", describeToken(p.currentToken()))
 		}
 
-		left = led_fn(p, left, type_bp_lu[p.currentTokenKind()])
+		left = led_fn(p, left, p.grammar.type_bp_lu[p.currentTokenKind()])
 	}
 
 	// While we have a LED and the current bp is < bp of current token
@@ -151,3 +153,42 @@ func parse_type(p *parser, bp binding_power) ast.Type {
 	// Move the return statement outside of the loop
 	return left
 }
+
+/*
+This function, `parse_qualified_type`, is the type left-denotation handler of `.` and parses a
+qualified type name such as `fs.FileInfo`. It returns an `ast.QualifiedType` with the type to
+the left of the dot as its qualifier.
+*/
+func parse_qualified_type(p *parser, left ast.Type, bp binding_power) ast.Type {
+	p.expect(lexer.DOT)
+	return ast.QualifiedType{
+		Qualifier: left,
+		Name:      p.expectError(lexer.IDENTIFIER, "type name").Value,
+	}
+}
+
+/*
+This function, `parse_generic_type`, is the type left-denotation handler of `<` and parses the
+type arguments of a generic type such as `Map<string, []number>`. Arguments are separated by
+commas, a trailing comma before `>` is allowed, and at least one argument is required.
+Implicit semicolons after arguments that end a line are skipped as in `parse_argument_list`.
+It returns an `ast.GenericType`.
+*/
+func parse_generic_type(p *parser, left ast.Type, bp binding_power) ast.Type {
+	p.expect(lexer.LESS)
+	arguments := []ast.Type{parse_type(p, default_bp)}
+
+	for p.skipImplicitSemicolons(); p.currentTokenKind() == lexer.COMMA; p.skipImplicitSemicolons() {
+		p.advance()
+		if p.currentTokenKind() == lexer.GREATER {
+			break
+		}
+		arguments = append(arguments, parse_type(p, default_bp))
+	}
+
+	p.expectError(lexer.GREATER, "',' or '>'")
+	return ast.GenericType{
+		Base:      left,
+		Arguments: arguments,
+	}
+}